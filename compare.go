@@ -1,8 +1,10 @@
 package describe
 
 import (
+	"bytes"
 	"io"
 	"os"
+	"reflect"
 )
 
 var diffFunc func(out io.Writer, a, b string)
@@ -21,9 +23,19 @@ func DiffFunc(f func(out io.Writer, a, b string)) {
 
 // Compare converts two values to their initialization format and then optionally outputs a diff between the two
 // representations if the they are different.  Returns true if the representations of the two values are the same.
+// Parts of the values of types with an equality function registered with RegisterEqual are compared with that
+// function instead.
 func Compare(a, b interface{}) bool {
-	astr := Value(a)
-	bstr := Value(b)
+	return (&Describer{}).Compare(a, b)
+}
+
+// Compare compares two values as the Compare function does, using the Describer's options, Formatters and
+// equality functions.
+func (d *Describer) Compare(a, b interface{}) bool {
+	c := &comparison{record: true, parts: map[string]reflect.Value{}}
+	astr := d.compareValue(a, c)
+	c.record = false
+	bstr := d.compareValue(b, c)
 	if astr == bstr {
 		return true
 	}
@@ -34,3 +46,12 @@ func Compare(a, b interface{}) bool {
 
 	return false
 }
+
+func (d *Describer) compareValue(v interface{}, c *comparison) string {
+	var buf bytes.Buffer
+	p := &goPrinter{}
+	w := walker{p: p, d: d, seen: refs{}, cmp: c}
+	w.walk(reflect.TypeOf(v), reflect.ValueOf(v), "")
+	layout(&buf, cat(p.docs...), d.width(), d.Align)
+	return buf.String()
+}
@@ -0,0 +1,127 @@
+package describe
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+)
+
+// TypeDecls returns declarations, such as "type Name struct { ... }", of the type of a value and of every
+// named type that it refers to through fields, elements, keys and the parameters and results of functions
+// and methods.  Each type is declared once, after the types that it refers to, and declarations are
+// separated by blank lines.  Types are declared with their names alone, as in their own packages.
+// Predeclared types such as int and error and the types of the standard library are not declared, and
+// instantiations of generic types, which cannot be declared, are written as comments.
+func TypeDecls(v interface{}) string {
+	return (&Describer{}).TypeDecls(v)
+}
+
+// TypeDecls returns the declarations of the types of a value as the TypeDecls function does, laid out
+// with the Describer's options.
+func (d *Describer) TypeDecls(v interface{}) string {
+	return d.typeDecls(declOrder(reflect.TypeOf(v)))
+}
+
+// typeDecls returns the declarations of types in order.
+func (d *Describer) typeDecls(types []reflect.Type) string {
+	var buf bytes.Buffer
+	for i, t := range types {
+		if i > 0 {
+			buf.WriteString("\n\n")
+		}
+		decl := d.withMethodSets(cat(textf("type %s ", typeArgs(t.Name())), typeDoc(t, 0, false)), t)
+		if !strings.Contains(t.Name(), "[") {
+			layout(&buf, decl, d.width(), d.Align)
+			continue
+		}
+		var inst bytes.Buffer
+		layout(&inst, decl, d.width(), d.Align)
+		for j, line := range strings.Split(inst.String(), "\n") {
+			if j > 0 {
+				buf.WriteString("\n")
+			}
+			if strings.HasPrefix(line, "\t") {
+				buf.WriteString("//" + line)
+			} else {
+				buf.WriteString("// " + line)
+			}
+		}
+	}
+	return buf.String()
+}
+
+// declared reports whether a type is one that TypeDecls declares.
+func declared(t reflect.Type) bool {
+	return t.Name() != "" && t.PkgPath() != "" && !standard(t.PkgPath())
+}
+
+// standard reports whether an import path is that of a package of the standard library, which, unlike
+// those of modules, have no dot in their first element.  Commands are in package main.
+func standard(path string) bool {
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		path = path[:i]
+	}
+	return !strings.Contains(path, ".") && path != "main"
+}
+
+// declOrder returns the named types that types refer to, and the types themselves if they are named, each
+// after the types that it refers to.  Types that refer to each other are in the order in which they are
+// found.
+func declOrder(roots ...reflect.Type) []reflect.Type {
+	var order []reflect.Type
+	seen := map[reflect.Type]bool{}
+	var add func(t reflect.Type)
+	add = func(t reflect.Type) {
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		typeRefs(t, add)
+		order = append(order, t)
+	}
+	for _, root := range roots {
+		if root == nil {
+			continue
+		}
+		if declared(root) {
+			add(root)
+		} else {
+			typeRefs(root, add)
+		}
+	}
+	return order
+}
+
+// typeRefs calls f for each named type that the definition of a type refers to, looking through the
+// unnamed types that it is made of.
+func typeRefs(t reflect.Type, f func(reflect.Type)) {
+	ref := func(t reflect.Type) {
+		if declared(t) {
+			f(t)
+			return
+		}
+		typeRefs(t, f)
+	}
+	switch t.Kind() {
+	case reflect.Array, reflect.Chan, reflect.Ptr, reflect.Slice:
+		ref(t.Elem())
+	case reflect.Map:
+		ref(t.Key())
+		ref(t.Elem())
+	case reflect.Func:
+		for i := 0; i < t.NumIn(); i++ {
+			ref(t.In(i))
+		}
+		for i := 0; i < t.NumOut(); i++ {
+			ref(t.Out(i))
+		}
+	case reflect.Interface:
+		for i := 0; i < t.NumMethod(); i++ {
+			ref(t.Method(i).Type)
+		}
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			ref(t.Field(i).Type)
+		}
+	}
+}
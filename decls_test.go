@@ -0,0 +1,101 @@
+package describe
+
+import (
+	"io/fs"
+	"testing"
+	"time"
+)
+
+type Payload struct {
+	ID     PayloadID
+	Items  []Item
+	Lookup map[PayloadID]*Item
+	Notify func(Item) error
+}
+
+type PayloadID string
+
+type Item struct {
+	Name   string
+	Parent *Item
+	Kind   ItemKind
+}
+
+type ItemKind uint8
+
+func TestTypeDecls(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{
+			name: "basic",
+			v:    0,
+			want: "",
+		},
+		{
+			name: "named basic",
+			v:    PayloadID(""),
+			want: "type PayloadID string",
+		},
+		{
+			name: "reachable types",
+			v:    Payload{},
+			want: "type PayloadID string\n\n" +
+				"type ItemKind uint8\n\n" +
+				"type Item struct {\n\tName string\n\tParent *Item\n\tKind ItemKind\n}\n\n" +
+				"type Payload struct {\n\tID PayloadID\n\tItems []Item\n\tLookup map[PayloadID]*Item\n\tNotify func (Item) error\n}",
+		},
+		{
+			name: "unnamed root",
+			v:    map[ItemKind][]PayloadID{},
+			want: "type ItemKind uint8\n\ntype PayloadID string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TypeDecls(tt.v); got != tt.want {
+				t.Errorf("TypeDecls() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescriber_TypeDecls(t *testing.T) {
+	d := &Describer{Align: true}
+	want := "type ItemKind uint8\n\ntype Item struct {\n\tName   string\n\tParent *Item\n\tKind   ItemKind\n}"
+	if got := d.TypeDecls(&Item{}); got != want {
+		t.Errorf("Describer.TypeDecls() = %q, want %q", got, want)
+	}
+}
+
+type Schedule struct {
+	Every time.Duration
+	Mode  fs.FileMode
+	Slot  Tuple[ItemKind, PayloadID]
+}
+
+func TestTypeDecls_foreign(t *testing.T) {
+	want := "type ItemKind uint8\n\n" +
+		"type PayloadID string\n\n" +
+		"// type Tuple[ItemKind, PayloadID] struct {\n//\tKey ItemKind\n//\tValue PayloadID\n// }\n\n" +
+		"type Schedule struct {\n\tEvery time.Duration\n\tMode io/fs.FileMode\n\tSlot Tuple[ItemKind, PayloadID]\n}"
+	if got := TypeDecls(Schedule{}); got != want {
+		t.Errorf("TypeDecls() = %q, want %q", got, want)
+	}
+}
+
+func Test_standard(t *testing.T) {
+	for path, want := range map[string]bool{
+		"time":                             true,
+		"io/fs":                            true,
+		"main":                             false,
+		"github.com/tjmerritt/go-describe": false,
+		"example.com":                      false,
+	} {
+		if got := standard(path); got != want {
+			t.Errorf("standard(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
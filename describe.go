@@ -6,6 +6,8 @@ import (
 	"io"
 	"reflect"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -13,41 +15,56 @@ type packageType int
 
 // Type returns a string that could be used to define a type
 func Type(v interface{}) string {
-	var buf bytes.Buffer
-	describeType(&buf, reflect.TypeOf(v), 0, false)
-	return buf.String()
+	return defaultDescriber.Type(v)
 }
 
-func describeFuncParams(f io.Writer, t reflect.Type, level int) {
-	fmt.Fprintf(f, "(")
+// FprintType writes what Type returns for a value to w, returning the first error from w.
+func FprintType(w io.Writer, v interface{}) error {
+	return defaultDescriber.FprintType(w, v)
+}
+
+// Fprint writes what Value returns for a value to w, returning the first error from w.
+func Fprint(w io.Writer, v interface{}) error {
+	return defaultDescriber.Fprint(w, v)
+}
+
+// funcParamsDoc returns the parameter and result lists of a function type.
+func funcParamsDoc(t reflect.Type, level int) *doc {
+	d := cat(text("("))
 
 	for i := 0; i < t.NumIn(); i++ {
 		if i > 0 {
-			fmt.Fprintf(f, ", ")
+			d.docs = append(d.docs, text(", "))
+		}
+		if i == t.NumIn()-1 && t.IsVariadic() {
+			d.docs = append(d.docs, text("..."), typeDoc(t.In(i).Elem(), level+1, true))
+			continue
 		}
-		describeType(f, t.In(i), level+1, true)
+		d.docs = append(d.docs, typeDoc(t.In(i), level+1, true))
 	}
 
-	fmt.Fprintf(f, ")")
+	d.docs = append(d.docs, text(")"))
 
 	if t.NumOut() > 0 {
-		fmt.Fprintf(f, " ")
+		d.docs = append(d.docs, text(" "))
 
 		if t.NumOut() > 1 {
-			fmt.Fprintf(f, "(")
+			d.docs = append(d.docs, text("("))
 		}
 
 		for i := 0; i < t.NumOut(); i++ {
 			if i > 0 {
-				fmt.Fprintf(f, ", ")
+				d.docs = append(d.docs, text(", "))
 			}
-			describeType(f, t.Out(i), level+1, true)
+			d.docs = append(d.docs, typeDoc(t.Out(i), level+1, true))
 		}
 
 		if t.NumOut() > 1 {
-			fmt.Fprintf(f, ")")
+			d.docs = append(d.docs, text(")"))
 		}
 	}
+
+	return d
 }
 
 func typeName(t reflect.Type) string {
@@ -55,8 +72,9 @@ func typeName(t reflect.Type) string {
 	if name == "" {
 		return ""
 	}
+	name = typeArgs(name)
 	path := t.PkgPath()
-	if path == "" || path == reflect.TypeOf(packageType(0)).PkgPath() {
+	if path == "" || path == thisPackage {
 		if name == "bool" || name == "int" || name == "string" {
 			return ""
 		}
@@ -66,280 +84,403 @@ func typeName(t reflect.Type) string {
 }
 
 func describeType(f io.Writer, t reflect.Type, level int, name bool) {
+	layout(f, typeDoc(t, level, name), 0, false)
+}
+
+// typeDoc returns a document describing a type.  The lines of struct and interface types are indented
+// from the given level.
+func typeDoc(t reflect.Type, level int, name bool) *doc {
 	if t == nil {
-		fmt.Fprintf(f, "nil")
-		return
+		return text("nil")
 	}
 
 	k := t.Kind()
 	//        fmt.Printf("kind %s name %s\n", k.String(), t.Name())
 
 	if name {
-		tn := typeName(t)
-		if tn != "" {
-			fmt.Fprintf(f, "%s", tn)
-			return
+		if tn := planOf(t).name; tn != "" {
+			return text(tn)
 		}
+	} else if d := selfTypeDoc(t, level); d != nil {
+		return d
 	}
 
 	switch k {
 	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
 		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64,
 		reflect.Complex64, reflect.Complex128, reflect.String:
-		fmt.Fprintf(f, "%s", k.String())
+		return text(k.String())
 	case reflect.Array:
-		fmt.Fprintf(f, "[%d]", t.Len())
-		describeType(f, t.Elem(), level+1, true)
+		return cat(textf("[%d]", t.Len()), typeDoc(t.Elem(), level+1, true))
 	case reflect.Chan:
-		fmt.Fprintf(f, "%s ", t.ChanDir().String())
-		describeType(f, t.Elem(), level+1, true)
+		elem := typeDoc(t.Elem(), level+1, true)
+		if t.ChanDir() == reflect.BothDir && t.Elem().Kind() == reflect.Chan && t.Elem().ChanDir() == reflect.RecvDir &&
+			typeName(t.Elem()) == "" {
+			// chan <-chan T would be read as chan<- chan T.
+			elem = cat(text("("), elem, text(")"))
+		}
+		return cat(textf("%s ", t.ChanDir().String()), elem)
 	case reflect.Func:
-		fmt.Fprintf(f, "func ")
-		describeFuncParams(f, t, level)
+		return cat(text("func "), funcParamsDoc(t, level))
 	case reflect.Interface:
-		fmt.Fprintf(f, "interface")
 		if t.NumMethod() == 0 {
-			fmt.Fprintf(f, "{}")
-		} else {
-			fmt.Fprintf(f, " {\n")
-
-			for i := 0; i < t.NumMethod(); i++ {
-				m := t.Method(i)
-
-				if m.Type.Kind() == reflect.Func {
-					fmt.Fprintf(f, "%s%s", indent(level+1), m.Name)
-					describeFuncParams(f, m.Type, level+1)
+			return text("interface{}")
+		}
 
-				} else {
-					fmt.Fprintf(f, "%s%s ", indent(level+1), m.Name)
-					describeType(f, m.Type, level+1, true)
-				}
+		var methods []*doc
+		for i := 0; i < t.NumMethod(); i++ {
+			m := t.Method(i)
 
-				fmt.Fprintf(f, "\n")
+			if m.Type.Kind() == reflect.Func {
+				methods = append(methods, fieldRow(nil, cat(text(m.Name), funcParamsDoc(m.Type, level+1)), nil))
+			} else {
+				methods = append(methods, fieldRow(text(m.Name), typeDoc(m.Type, level+1, true), nil))
 			}
-
-			fmt.Fprintf(f, "%s}", indent(level))
 		}
+
+		return blockDoc("interface", methods, level)
 	case reflect.Map:
-		fmt.Fprintf(f, "map[")
-		describeType(f, t.Key(), level+1, true)
-		fmt.Fprintf(f, "]")
-		describeType(f, t.Elem(), level+1, true)
+		return cat(text("map["), typeDoc(t.Key(), level+1, true), text("]"), typeDoc(t.Elem(), level+1, true))
 	case reflect.Ptr:
-		fmt.Fprintf(f, "*")
-		describeType(f, t.Elem(), level+1, true)
+		return cat(text("*"), typeDoc(t.Elem(), level+1, true))
 	case reflect.Slice:
-		fmt.Fprintf(f, "[]")
-		describeType(f, t.Elem(), level+1, true)
+		return cat(text("[]"), typeDoc(t.Elem(), level+1, true))
 	case reflect.Struct:
-		fmt.Fprintf(f, "struct")
 		if t.NumField() == 0 {
-			fmt.Fprintf(f, "{}")
-		} else {
-			fmt.Fprintf(f, " {\n")
-			for i := 0; i < t.NumField(); i++ {
-				sf := t.Field(i)
-
-				if sf.Anonymous {
-					fmt.Fprintf(f, "%s", indent(level+1))
-				} else {
-					fmt.Fprintf(f, "%s%s ", indent(level+1), sf.Name)
-				}
+			return text("struct{}")
+		}
 
-				describeType(f, sf.Type, level+1, true)
+		var fields []*doc
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
 
-				if sf.Tag != "" {
-					fmt.Fprintf(f, " `%s`", sf.Tag)
-				}
+			var name, tag *doc
+			if !sf.Anonymous {
+				name = text(sf.Name)
+			}
 
-				fmt.Fprintf(f, "\n")
+			if sf.Tag != "" {
+				tag = textf("`%s`", sf.Tag)
 			}
 
-			fmt.Fprintf(f, "%s}", indent(level))
+			fields = append(fields, fieldRow(name, typeDoc(sf.Type, level+1, true), tag))
 		}
+
+		return blockDoc("struct", fields, level)
 	case reflect.UnsafePointer:
-		fmt.Fprintf(f, "unsafe.Pointer")
+		return text("unsafe.Pointer")
 	default:
-		fmt.Fprintf(f, "type of unknown kind %s", k.String())
+		return textf("type of unknown kind %s", k.String())
 	}
 }
 
+// blockDoc returns the document for a struct or interface type with the given fields or methods.
+func blockDoc(keyword string, rows []*doc, level int) *doc {
+	g := group(text(keyword), alt(" {", "{"), fieldRows(level+1, rows...), nest(level, brk(" ")), text("}"))
+	g.fields = true
+	return g
+}
+
 // Value returns a string that could be used to declare an initial value
 func Value(v interface{}) string {
-	var buf bytes.Buffer
-	describeValue(&buf, reflect.TypeOf(v), reflect.ValueOf(v), 0)
-	return buf.String()
+	return defaultDescriber.Value(v)
 }
 
+// defaultDescriber is the zero Describer, which describes values as Value does.  It is never changed.
+var defaultDescriber = &Describer{}
+
 func basicValue(t reflect.Type, v reflect.Value) string {
 	if t == nil {
 		return "nil"
 	}
 
-	k := t.Kind()
-	//        fmt.Printf("kind %s name %s\n", k.String(), t.Name())
-	i := v.Interface()
-
-	switch k {
-	case reflect.Bool:
-		if i.(bool) {
-			return "true"
-		}
-		return "false"
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return fmt.Sprintf("%d", i)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		return fmt.Sprintf("%d", i)
-	case reflect.Float32, reflect.Float64:
-		return fmt.Sprintf("%g", i)
-	case reflect.Complex64:
-		r := real(i.(complex64))
-		j := imag(i.(complex64))
-		if j != 0.0 {
-			return fmt.Sprintf("%g+%gi", r, j)
-		}
-		return fmt.Sprintf("%g", r)
-	case reflect.Complex128:
-		r := real(i.(complex128))
-		j := imag(i.(complex128))
-		if j != 0.0 {
-			return fmt.Sprintf("%g+%gi", r, j)
-		}
-		return fmt.Sprintf("%g", r)
-	case reflect.String:
-		// Should probably do some decoding of the string to make special characters visible, but this
-		// is good enough for now.
-		return fmt.Sprintf("\"%s\"", v)
+	// The value is read with the kind specific accessors rather than Interface so that values held in
+	// unexported fields can be described as well.
+	if k := t.Kind(); scalarKind(k) {
+		var a [64]byte
+		return string(appendBasic(a[:0], k, v))
 	}
 
 	return ""
 }
 
 func describeValue(f io.Writer, t reflect.Type, v reflect.Value, level int) {
-	if t == nil {
-		fmt.Fprintf(f, "nil")
+	defaultDescriber.describeValue(f, t, v, level)
+}
+
+// goPrinter is the Printer used by Value.  It builds a document that writes values as Go composite
+// literals, or lays it out as it is built if it has a stream.
+type goPrinter struct {
+	level  int
+	frames []goFrame
+	docs   []*doc  // the documents of the value once it is complete
+	out    *budget // the output of the description, counted from the text added to it
+	stream *stream // the layout of the value as it is walked, or nil if the document is built
+	opened int     // the number of composites, from the outermost, that are open in the stream
+}
+
+// goFrame is a struct, map, array or slice that is being printed.
+type goFrame struct {
+	kind    reflect.Kind
+	head    []*doc    // the type and opening brace
+	rows    []*doc    // the fields or elements, or the last of them if the composite is open
+	key     *doc      // the key of the map entry being printed
+	index   int       // the index of the next element of an array or slice
+	plan    *typePlan // the plan of a struct type
+	indent  int       // the level of the rows
+	n       int       // the number of rows
+	size    int       // the width of the composite written flat so far, if it is being sized
+	open    bool      // whether the head and all but the last row have been written to the stream
+	flat    bool      // whether an open composite is written on one line
+	started bool      // whether the start of the last row of an open composite has been written
+	rs      rowState  // the state of the rows of an open composite
+}
+
+// goScalar returns the Go expression for a value that Printers pass to Scalar.
+func goScalar(t reflect.Type, v reflect.Value) string {
+	var buf bytes.Buffer
+	p := &goPrinter{}
+	p.Scalar(t, v)
+	layout(&buf, cat(p.docs...), 0, false)
+	return buf.String()
+}
+
+// add adds a document to the value, key or element being printed.
+func (p *goPrinter) add(d *doc) {
+	if d.kind == docText {
+		p.out.add(len(d.text))
+	}
+	if p.sizing() {
+		p.grow(docWidth(d))
+	}
+	p.place(d)
+	if p.stream != nil {
+		p.openFrames()
+	}
+}
+
+// place places a document in the value, key or element being printed, or writes it to the stream if it
+// is the whole value.
+func (p *goPrinter) place(d *doc) {
+	if len(p.frames) == 0 {
+		if p.stream != nil {
+			p.stream.render(0, false, d)
+			return
+		}
+		p.docs = append(p.docs, d)
+		return
+	}
+	fr := p.top()
+	if fr.key != nil {
+		fr.key.docs = append(fr.key.docs, d)
 		return
 	}
+	r := fr.rows[len(fr.rows)-1]
+	r.docs[1].docs = append(r.docs[1].docs, d)
+}
 
-	k := t.Kind()
-	//        fmt.Printf("kind %s name %s\n", k.String(), t.Name())
-	tn := typeName(t)
+func (p *goPrinter) top() *goFrame {
+	return &p.frames[len(p.frames)-1]
+}
 
-	switch k {
-	case reflect.Bool, reflect.Int, reflect.String:
-		bv := basicValue(t, v)
-		if tn != "" {
-			fmt.Fprintf(f, "%s(%s)", tn, bv)
-		} else {
-			fmt.Fprintf(f, "%s", bv)
+// next starts the next field or element of the composite being printed.
+func (p *goPrinter) next(key *doc) {
+	fr := p.top()
+	if p.sizing() {
+		n := 0
+		if fr.n > 0 {
+			n += len(", ")
 		}
-	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint,
-		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32,
-		reflect.Float64, reflect.Complex64, reflect.Complex128:
-		bv := basicValue(t, v)
-		if tn == "" {
-			tn = k.String()
+		if key != nil {
+			n += len(": ")
+			if fr.kind != reflect.Map {
+				// The keys of map entries are added as they are printed.
+				n += docWidth(key)
+			}
 		}
-		fmt.Fprintf(f, "%s(%s)", tn, bv)
-	case reflect.Array:
-		describeType(f, t, level, true)
-		if v.Len() == 0 {
-			fmt.Fprintf(f, "{}")
-		} else {
-			fmt.Fprintf(f, "{\n")
+		p.grow(n)
+	}
+	fr.n++
+	if fr.open {
+		p.finishRow(fr)
+		fr.rows = fr.rows[:0]
+	}
+	fr.rows = append(fr.rows, elemRow(key, cat()))
+	if p.stream != nil {
+		p.openFrames()
+	}
+}
 
-			for j := 0; j < v.Len(); j++ {
-				fmt.Fprintf(f, "%s", indent(level+1))
-				describeValue(f, t.Elem(), v.Index(j), level+1)
-				fmt.Fprintf(f, ",\n")
-			}
+func (p *goPrinter) begin(t reflect.Type, k reflect.Kind, n int) bool {
+	plan := planOf(t)
+	td := plan.typeDoc(t)
+	if td == nil {
+		td = typeDoc(t, p.level, true)
+	}
+	if n == 0 {
+		p.add(cat(td, text("{}")))
+		return false
+	}
+	p.level++
+	p.frames = append(p.frames, goFrame{kind: k, head: []*doc{td, text("{")}, plan: plan, indent: p.level})
+	if p.sizing() {
+		p.grow(docWidth(td) + len("{}"))
+	}
+	return true
+}
 
-			fmt.Fprintf(f, "%s", indent(level))
-			fmt.Fprintf(f, "}")
-		}
+func (p *goPrinter) end() {
+	fr := p.frames[len(p.frames)-1]
+	p.frames = p.frames[:len(p.frames)-1]
+	if fr.open {
+		p.closeFrame(&fr)
+		p.opened--
+		p.level--
+		return
+	}
+	if len(fr.rows) == 0 {
+		// Every field or element was left out.
+		p.level--
+		p.place(cat(fr.head[0], text("{}")))
+		return
+	}
+	d := group(fr.head...)
+	d.docs = append(d.docs, elemRows(p.level, fr.rows...))
+	p.level--
+	d.docs = append(d.docs, nest(p.level, brk("")), text("}"))
+	p.place(d)
+}
+
+func (p *goPrinter) Scalar(t reflect.Type, v reflect.Value) {
+	k := t.Kind()
+	if scalarKind(k) {
+		var a [64]byte
+		p.add(text(string(appendScalar(a[:0], t, v))))
+		return
+	}
+
+	switch k {
 	case reflect.Chan:
-		fmt.Fprintf(f, "make(")
-		describeType(f, t, level, true)
+		d := cat(text("make("), typeDoc(t, p.level, true))
 		c := v.Cap()
 		if c > 0 {
-			fmt.Fprintf(f, ", %d)", c)
+			d.docs = append(d.docs, textf(", %d)", c))
 		} else {
-			fmt.Fprintf(f, ")")
+			d.docs = append(d.docs, text(")"))
 		}
+		p.add(d)
 	case reflect.Func:
-		fmt.Fprintf(f, "func ")
-		describeFuncParams(f, t, level)
-		fmt.Fprintf(f, " {func%d}", objectNumber(v))
-	case reflect.Interface:
-		describeType(f, t, level, true)
-		fmt.Fprintf(f, "{}")
-	case reflect.Map:
-		describeType(f, t, level, true)
-		if v.Len() == 0 {
-			fmt.Fprintf(f, "{}")
-		} else {
-			fmt.Fprintf(f, "{\n")
-
-			kt := t.Key()
-			keys := v.MapKeys()
-			sort.Slice(keys, func(i, j int) bool { return less(kt, keys[i], keys[j]) })
-			for _, k := range keys {
-				fmt.Fprintf(f, "%s", indent(level+1))
-				describeValue(f, t.Key(), k, level+1)
-				fmt.Fprintf(f, ": ")
-				describeValue(f, t.Elem(), v.MapIndex(k), level+1)
-				fmt.Fprintf(f, ",\n")
-			}
+		p.add(cat(text("func "), funcParamsDoc(t, p.level), textf(" {func%d}", objectNumber(v))))
+	case reflect.UnsafePointer:
+		p.add(textf("unsafe.Pointer(%x)", v.Pointer()))
+	default:
+		p.add(textf("type of unknown kind %s", k.String()))
+	}
+}
 
-			fmt.Fprintf(f, "%s}", indent(level))
-		}
-	case reflect.Ptr:
-		fmt.Fprintf(f, "&")
-		describeValue(f, t.Elem(), v.Elem(), level+1)
-	case reflect.Slice:
-		describeType(f, t, level, true)
-		if v.Len() == 0 {
-			fmt.Fprintf(f, "{}")
-		} else {
-			fmt.Fprintf(f, "{\n")
+func (p *goPrinter) Nil(t reflect.Type) {
+	p.add(text("nil"))
+}
 
-			for j := 0; j < v.Len(); j++ {
-				fmt.Fprintf(f, "%s", indent(level+1))
-				describeValue(f, t.Elem(), v.Index(j), level+1)
-				fmt.Fprintf(f, ",\n")
-			}
+func (p *goPrinter) Ref(t reflect.Type, path string) {
+	p.add(textf("nil /* cycle: %s */", pathString(path)))
+}
 
-			fmt.Fprintf(f, "%s}", indent(level))
-		}
-	case reflect.Struct:
-		describeType(f, t, level, true)
-		fmt.Fprintf(f, "{\n")
+func (p *goPrinter) Pointer(t reflect.Type) {
+	p.add(text("&"))
+}
 
-		for i := 0; i < t.NumField(); i++ {
-			sf := t.Field(i)
-			fv := v.Field(i)
+func (p *goPrinter) BeginStruct(t reflect.Type, v reflect.Value) bool {
+	return p.begin(t, reflect.Struct, t.NumField())
+}
 
-			fmt.Fprintf(f, "%s", indent(level+1))
-			if !sf.Anonymous {
-				if sf.PkgPath != "" && sf.PkgPath != reflect.TypeOf(packageType(0)).PkgPath() {
-					fmt.Fprintf(f, "%s.%s: ", sf.PkgPath, sf.Name)
-				} else {
-					fmt.Fprintf(f, "%s: ", sf.Name)
-				}
-			}
-			if sf.Name == "" || ('A' <= sf.Name[0] && sf.Name[0] <= 'Z') {
-				describeValue(f, sf.Type, fv, level+1)
-			} else {
-				fmt.Fprintf(f, "...")
-			}
-			fmt.Fprintf(f, ",\n")
-		}
+func (p *goPrinter) Field(sf reflect.StructField) bool {
+	p.next(p.top().plan.keys[sf.Index[0]])
+	if sf.PkgPath != "" {
+		p.add(text("..."))
+		return false
+	}
+	return true
+}
 
-		fmt.Fprintf(f, "%s}", indent(level))
-	case reflect.UnsafePointer:
-		fmt.Fprintf(f, "unsafe.Pointer(%x)", v.Pointer())
-	default:
-		fmt.Fprintf(f, "type of unknown kind %s", k.String())
+func (p *goPrinter) EndStruct(t reflect.Type) {
+	p.end()
+}
+
+func (p *goPrinter) BeginList(t reflect.Type, v reflect.Value) bool {
+	return p.begin(t, t.Kind(), v.Len())
+}
+
+func (p *goPrinter) Elem(i int) {
+	fr := p.top()
+	if fr.kind == reflect.Map {
+		key := fr.key
+		fr.key = nil
+		p.next(key)
+		return
+	}
+	// Elements that follow elements left out are written with their indices.
+	var key *doc
+	if i != fr.index {
+		key = textf("%d", i)
+	}
+	fr.index = i + 1
+	p.next(key)
+}
+
+func (p *goPrinter) EndList(t reflect.Type) {
+	p.end()
+}
+
+func (p *goPrinter) BeginMap(t reflect.Type, v reflect.Value) bool {
+	return p.begin(t, reflect.Map, v.Len())
+}
+
+func (p *goPrinter) Key(i int, k reflect.Value) bool {
+	p.top().key = cat()
+	return true
+}
+
+func (p *goPrinter) EndMap(t reflect.Type) {
+	p.end()
+}
+
+func (p *goPrinter) Text(t reflect.Type, s string) {
+	p.add(text(s))
+}
+
+func (p *goPrinter) More(n int) {
+	fr := p.top()
+	what := "more element"
+	if fr.kind == reflect.Map {
+		what = "more entry"
+	}
+	r := fr.rows[len(fr.rows)-1]
+	r.docs[1].docs = append(r.docs[1].docs, textf(" /* %s */", count(n, what)))
+}
+
+func (p *goPrinter) addDoc(d *doc) {
+	p.add(d)
+}
+
+func (p *goPrinter) indentLevel() int {
+	return p.level
+}
+
+func (p *goPrinter) frameCount() int {
+	return len(p.frames)
+}
+
+// unwind discards the composites being printed beyond the first n, or closes those that have been written
+// in part to the stream.
+func (p *goPrinter) unwind(n int) {
+	for len(p.frames) > n {
+		if p.top().open {
+			p.end()
+			continue
+		}
+		p.level--
+		p.frames = p.frames[:len(p.frames)-1]
 	}
 }
 
@@ -378,6 +519,142 @@ func objectNumber(v reflect.Value) int {
 	return n
 }
 
+// sortedKeys returns the keys of the map v in the order in which they are described.  Keys of kinds
+// without a natural order are ordered by their descriptions, and keys that are not ordered one way or the
+// other, such as pointers to equal values and NaNs, by the descriptions of their values, so that the order
+// does not depend on addresses or on the order of iteration.
+func sortedKeys(t reflect.Type, v reflect.Value) []reflect.Value {
+	kt := t.Key()
+	keys := make([]reflect.Value, 0, v.Len())
+	values := make([]reflect.Value, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		keys = append(keys, iter.Key())
+		values = append(values, iter.Value())
+	}
+	describe := func(t reflect.Type, v reflect.Value) string {
+		var buf bytes.Buffer
+		describeValue(&buf, t, v, 0)
+		return buf.String()
+	}
+	// The descriptions are of the keys and values in the order of iteration.  Those of values are made
+	// only for keys that are not ordered.
+	var keyDescs []string
+	switch kt.Kind() {
+	case reflect.Bool, reflect.Complex64, reflect.Complex128, reflect.Array, reflect.Chan, reflect.Interface,
+		reflect.Ptr, reflect.Struct, reflect.UnsafePointer:
+		keyDescs = make([]string, len(keys))
+		for i, k := range keys {
+			keyDescs[i] = describe(kt, k)
+		}
+	}
+	valueDescs := make([]*string, len(keys))
+	compare := func(i, j int) int {
+		switch {
+		case keyDescs != nil:
+			if keyDescs[i] != keyDescs[j] {
+				return strings.Compare(keyDescs[i], keyDescs[j])
+			}
+		case less(kt, keys[i], keys[j]):
+			return -1
+		case less(kt, keys[j], keys[i]):
+			return 1
+		}
+		for _, k := range []int{i, j} {
+			if valueDescs[k] == nil {
+				d := describe(t.Elem(), values[k])
+				valueDescs[k] = &d
+			}
+		}
+		return strings.Compare(*valueDescs[i], *valueDescs[j])
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return compare(order[a], order[b]) < 0 })
+	sorted := make([]reflect.Value, len(keys))
+	for i, k := range order {
+		sorted[i] = keys[k]
+	}
+	return sorted
+}
+
+// refs records the pointers, maps and slices on the path currently being described so that cyclic values
+// terminate.  Each reference maps to the path at which it was entered.
+type refs map[refKey]string
+
+type refKey struct {
+	t   reflect.Type
+	ptr uintptr
+	len int
+}
+
+func refOf(v reflect.Value) (refKey, bool) {
+	if !v.IsValid() {
+		return refKey{}, false
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		if v.IsNil() {
+			return refKey{}, false
+		}
+		return refKey{t: v.Type(), ptr: v.Pointer()}, true
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return refKey{}, false
+		}
+		return refKey{t: v.Type(), ptr: v.Pointer(), len: v.Len()}, true
+	}
+	return refKey{}, false
+}
+
+// enter records that v is being described at path.  If v is already being described further up the path,
+// enter returns the path at which it was entered and false.
+func (r refs) enter(v reflect.Value, path string) (string, bool) {
+	k, ok := refOf(v)
+	if !ok {
+		return "", true
+	}
+	if p, found := r[k]; found {
+		return p, false
+	}
+	r[k] = path
+	return "", true
+}
+
+// leave undoes a successful enter.
+func (r refs) leave(v reflect.Value) {
+	if k, ok := refOf(v); ok {
+		delete(r, k)
+	}
+}
+
+// Paths are written as Go selector and index expressions relative to the value being described, so the
+// path of the described value itself is empty.
+
+func fieldPath(path, name string) string {
+	return path + "." + name
+}
+
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+func keyPath(path string, k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return path + "[" + strconv.Quote(k.String()) + "]"
+	}
+	return path + "[" + fmt.Sprint(k) + "]"
+}
+
+func pathString(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
 func less(t reflect.Type, a, b reflect.Value) bool {
 	k := t.Kind()
 	if k == reflect.String {
@@ -386,7 +663,8 @@ func less(t reflect.Type, a, b reflect.Value) bool {
 	if k == reflect.Int || k == reflect.Int8 || k == reflect.Int16 || k == reflect.Int32 || k == reflect.Int64 {
 		return a.Int() < b.Int()
 	}
-	if k == reflect.Uint || k == reflect.Uint8 || k == reflect.Uint16 || k == reflect.Uint32 || k == reflect.Uint64 {
+	if k == reflect.Uint || k == reflect.Uint8 || k == reflect.Uint16 || k == reflect.Uint32 || k == reflect.Uint64 ||
+		k == reflect.Uintptr {
 		return a.Uint() < b.Uint()
 	}
 	if k == reflect.Float32 || k == reflect.Float64 {
@@ -394,3 +672,23 @@ func less(t reflect.Type, a, b reflect.Value) bool {
 	}
 	return false
 }
+
+// typeArgs rewrites the type arguments in the name of an instantiated generic type, which reflect writes
+// with the import paths of their packages and without spaces, as in Pair[int,example.com/x.T], so that
+// their names are written as other type names are.
+func typeArgs(name string) string {
+	i := strings.IndexByte(name, '[')
+	if i < 0 {
+		return name
+	}
+	args := name[i:]
+	args = strings.ReplaceAll(args, thisPackage+".", "")
+	var b strings.Builder
+	for j := 0; j < len(args); j++ {
+		b.WriteByte(args[j])
+		if args[j] == ',' && j+1 < len(args) && args[j+1] != ' ' {
+			b.WriteByte(' ')
+		}
+	}
+	return name[:i] + b.String()
+}
@@ -2,9 +2,13 @@ package describe
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 	"unsafe"
 )
 
@@ -28,6 +32,11 @@ var nested = struct {
 	}{10},
 }
 
+type Tuple[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
 func (o *Obj) Method(x int) string {
 	return fmt.Sprintf("%d", o.Field+x)
 }
@@ -248,6 +257,41 @@ func TestType(t *testing.T) {
 			},
 			want: "func (int, int)",
 		},
+		{
+			name: "variadic func",
+			args: args{
+				v: func(string, ...int) {},
+			},
+			want: "func (string, ...int)",
+		},
+		{
+			name: "chan of recv chan",
+			args: args{
+				v: make(chan (<-chan int)),
+			},
+			want: "chan (<-chan int)",
+		},
+		{
+			name: "send chan of chan",
+			args: args{
+				v: make(chan<- chan int),
+			},
+			want: "chan<- chan int",
+		},
+		{
+			name: "generic instantiation",
+			args: args{
+				v: Tuple[Obj, time.Duration]{},
+			},
+			want: "struct {\n\tKey Obj\n\tValue time.Duration\n}",
+		},
+		{
+			name: "pointer to generic instantiation",
+			args: args{
+				v: &Tuple[Obj, time.Duration]{},
+			},
+			want: "*Tuple[Obj, time.Duration]",
+		},
 		{
 			name: "named int",
 			args: args{
@@ -583,6 +627,20 @@ func TestValue(t *testing.T) {
 			},
 			want: "make(<-chan int)",
 		},
+		{
+			name: "chan of recv chan",
+			args: args{
+				v: make(chan (<-chan int)),
+			},
+			want: "make(chan (<-chan int))",
+		},
+		{
+			name: "generic instantiation",
+			args: args{
+				v: Tuple[string, []Obj]{Key: "k"},
+			},
+			want: "Tuple[string, []Obj]{\n\tKey: \"k\",\n\tValue: []Obj{},\n}",
+		},
 		{
 			name: "map",
 			args: args{
@@ -601,6 +659,27 @@ func TestValue(t *testing.T) {
 			want: `map[int]int{
 	1: 2,
 	3: 4,
+}`,
+		},
+		{
+			name: "map with struct keys",
+			args: args{
+				v: map[Obj]bool{
+					{Field: 2}: true,
+					{Field: 1}: false,
+					{Field: 3}: true,
+				},
+			},
+			want: `map[Obj]bool{
+	Obj{
+		Field: 1,
+	}: false,
+	Obj{
+		Field: 2,
+	}: true,
+	Obj{
+		Field: 3,
+	}: true,
 }`,
 		},
 		{
@@ -720,6 +799,41 @@ func TestValue(t *testing.T) {
 			},
 			want: "unsafe.Pointer(0)",
 		},
+		{
+			name: "pointer to struct",
+			args: args{
+				v: &Obj{Field: 1},
+			},
+			want: "&Obj{\n\tField: 1,\n}",
+		},
+		{
+			name: "slice of interfaces",
+			args: args{
+				v: []interface{}{1, "a", nil},
+			},
+			want: "[]interface{}{\n\t1,\n\t\"a\",\n\tnil,\n}",
+		},
+		{
+			name: "empty struct",
+			args: args{
+				v: struct{}{},
+			},
+			want: "struct{}{}",
+		},
+		{
+			name: "nil pointer",
+			args: args{
+				v: (*int)(nil),
+			},
+			want: "nil",
+		},
+		{
+			name: "cycle",
+			args: args{
+				v: []Node{*cyclicNode()},
+			},
+			want: "[]Node{\n\tNode{\n\t\tName: \"a\",\n\t\tNext: &Node{\n\t\t\tName: \"a\",\n\t\t\tNext: nil /* cycle: [0].Next */,\n\t\t},\n\t},\n}",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -730,6 +844,34 @@ func TestValue(t *testing.T) {
 	}
 }
 
+// The fields and elements of values behind pointers are indented one level deeper than the line of the
+// pointer, as gofmt indents them.  Before the Printer interface, they were indented a level deeper still.
+func TestValue_pointerIndentation(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{
+			name: "field",
+			v:    Node{Name: "a", Next: &Node{Name: "b"}},
+			want: "Node{\n\tName: \"a\",\n\tNext: &Node{\n\t\tName: \"b\",\n\t\tNext: nil,\n\t},\n}",
+		},
+		{
+			name: "elements",
+			v:    []*Node{{Name: "a", Next: &Node{Name: "b"}}},
+			want: "[]*Node{\n\t&Node{\n\t\tName: \"a\",\n\t\tNext: &Node{\n\t\t\tName: \"b\",\n\t\t\tNext: nil,\n\t\t},\n\t},\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Value(tt.v); got != tt.want {
+				t.Errorf("Value() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_describeValue(t *testing.T) {
 	type args struct {
 		t     reflect.Type
@@ -753,3 +895,53 @@ func Test_describeValue(t *testing.T) {
 		})
 	}
 }
+
+func TestFprint(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       interface{}
+		limit   int
+		wantErr bool
+	}{
+		{name: "scalar", v: 42, limit: 100},
+		{name: "struct", v: Obj{Field: 3}, limit: 100},
+		{name: "large", v: make([]int, 10000), limit: 1 << 20},
+		{name: "failing", v: make([]int, 10000), limit: 100, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			err := Fprint(&limitedWriter{w: &b, n: tt.limit}, tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fprint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if want := Value(tt.v); !tt.wantErr && b.String() != want {
+				t.Errorf("Fprint() wrote %q, want %q", b.String(), want)
+			}
+		})
+	}
+}
+
+func TestFprintType(t *testing.T) {
+	var b strings.Builder
+	if err := FprintType(&b, Obj{}); err != nil || b.String() != Type(Obj{}) {
+		t.Errorf("FprintType() = %v, wrote %q, want %q", err, b.String(), Type(Obj{}))
+	}
+	if err := FprintType(&limitedWriter{w: &b}, Obj{}); err == nil {
+		t.Errorf("FprintType() to a full writer succeeded")
+	}
+}
+
+// limitedWriter writes n bytes to w and then fails.
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > lw.n {
+		return 0, errors.New("full")
+	}
+	lw.n -= len(p)
+	return lw.w.Write(p)
+}
@@ -0,0 +1,160 @@
+package describe
+
+import (
+	"io"
+	"reflect"
+)
+
+// A Describer describes types and values with options that control the form of the description.  The zero
+// Describer describes them in the same way as Type and Value.
+type Describer struct {
+	// Width is the width of line that descriptions are fitted into.  Composite values and struct and
+	// interface types that fit in what remains of a line are written on it, and those that do not have
+	// their elements written on lines of their own.  A Width of zero writes every element on a line of its
+	// own.
+	Width int
+
+	// Compact writes every description on a single line, as is suited to log messages.
+	Compact bool
+
+	// Align aligns the values of struct fields and map entries, and the types and tags of struct type
+	// fields, in columns as gofmt would, so that descriptions match gofmt formatted source.
+	Align bool
+
+	// Methods selects whether values are described with what their Error, String or MarshalText methods
+	// return.
+	Methods MethodPolicy
+
+	// GoString describes values with what their GoString methods return, which is Go.
+	GoString bool
+
+	// Unsigned selects the format in which values of unsigned integer types are written.
+	Unsigned NumberFormat
+
+	// Runes writes values of type rune as character literals.
+	Runes bool
+
+	// Bytes writes arrays and slices of bytes on a single line, slices of text as conversions of string
+	// literals and anything else as hexadecimal bytes.
+	Bytes bool
+
+	// Redact holds the rules by which secrets are redacted, or is nil to redact only the values of fields
+	// with the describe tag option redact.
+	Redact *Redaction
+
+	// Limits bound the size of descriptions.
+	Limits Limits
+
+	// OmitZero leaves out struct fields that hold the zero values of their types.
+	OmitZero bool
+
+	// SparseArrays writes arrays and slices of which at least half the elements are zero values with the
+	// indices of their elements, leaving out the zero values, as in [1024]byte{0: 1, 5: 2}.  The last
+	// element of a slice is always written, so that the literal has the slice's length.  Printers other
+	// than that of Value are given every element.
+	SparseArrays bool
+
+	// MethodSets follows the descriptions of named types written by Type and TypeDecls with declarations
+	// of their exported methods, with receivers of the type or of pointers to it as they are declared.
+	MethodSets bool
+
+	// Interfaces are the interface types that the descriptions of method sets report whether types
+	// implement, such as reflect.TypeOf((*fmt.Stringer)(nil)).Elem().
+	Interfaces []reflect.Type
+
+	// Safe recovers from panics while describing the parts of values, such as in methods and
+	// Formatters, writing a /* panic: ... */ comment in place of a part that panics and going on with the
+	// rest of the value.
+	Safe bool
+
+	registry // the Formatters and equality functions registered with the Describer
+}
+
+// width returns the width to lay out documents in.
+func (d *Describer) width() int {
+	if d.Compact {
+		return -1
+	}
+	return d.Width
+}
+
+// Type returns a string that could be used to define the type of a value.
+func (d *Describer) Type(v interface{}) string {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	d.FprintType(buf, v)
+	return buf.String()
+}
+
+// Value returns a string that could be used to declare an initial value.
+func (d *Describer) Value(v interface{}) string {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	d.Fprint(buf, v)
+	return buf.String()
+}
+
+// FprintType writes what Type returns for a value to w as it is laid out.  Writing stops at the first error
+// from w, which is returned.
+func (d *Describer) FprintType(w io.Writer, v interface{}) error {
+	bw, flush := bufferWriter(w)
+	t := reflect.TypeOf(v)
+	layout(bw, d.withMethodSets(typeDoc(t, 0, false), t), d.width(), d.Align)
+	return flush()
+}
+
+// Fprint writes what Value returns for a value to w as the value is walked, without building the whole of
+// its description.  A struct, map, array or slice is written once it is known not to fit on a line, and
+// then each of its fields and elements once it has been walked, so what is held in memory is no more than
+// a line or so for each level of nesting, and the key of a map entry.  When aligning, each section of
+// aligned rows is held until it ends.  Writing, and the walk, stop at the first error from w, which is
+// returned.
+func (d *Describer) Fprint(w io.Writer, v interface{}) error {
+	bw, flush := bufferWriter(w)
+	d.describeValue(bw, reflect.TypeOf(v), reflect.ValueOf(v), 0)
+	return flush()
+}
+
+// describeValue writes the description of a value, returning the first panic recovered from if the
+// Describer is Safe.
+func (d *Describer) describeValue(f io.Writer, t reflect.Type, v reflect.Value, level int) error {
+	if t != nil && scalarKind(t.Kind()) && d.Limits.Output <= 0 {
+		// Scalars described by their kind are written without building a document.
+		w := walker{d: d}
+		if w.formatter(t, v) == nil {
+			b := scalars.Get().(*[]byte)
+			*b = appendScalar((*b)[:0], t, v)
+			f.Write(*b)
+			scalars.Put(b)
+			return nil
+		}
+	}
+	p := &goPrinter{level: level}
+	w := walker{p: p, d: d, seen: refs{}}
+	if d.Safe {
+		w.safe = &safety{}
+	}
+	var lw *limitWriter
+	if d.Limits.Output > 0 {
+		p.out = &budget{max: d.Limits.Output}
+		w.out = p.out
+		lw = &limitWriter{w: f, max: d.Limits.Output}
+		f = lw
+	}
+	p.stream = newStream(f, d.width(), d.Align)
+	w.stream = p.stream
+	w.walk(t, v, "")
+	// A walk that is stopped leaves composites open, which are closed after it.
+	for len(p.frames) > 0 {
+		p.end()
+	}
+	p.stream.close()
+	if lw != nil {
+		lw.partial = w.err == errOutputLimit
+		lw.close()
+	}
+	if w.safe != nil && w.safe.err != nil {
+		return w.safe.err
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package describe
+
+import (
+	"go/format"
+	"testing"
+)
+
+func TestDescriber_Value(t *testing.T) {
+	type fields struct {
+		Width   int
+		Compact bool
+		Align   bool
+	}
+	type args struct {
+		v interface{}
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   string
+	}{
+		{
+			name: "zero describer",
+			args: args{
+				v: []int{1, 2},
+			},
+			want: "[]int{\n\t1,\n\t2,\n}",
+		},
+		{
+			name: "slice fits",
+			fields: fields{
+				Width: 20,
+			},
+			args: args{
+				v: []int{1, 2, 3},
+			},
+			want: "[]int{1, 2, 3}",
+		},
+		{
+			name: "slice does not fit",
+			fields: fields{
+				Width: 10,
+			},
+			args: args{
+				v: []int{1, 2, 3},
+			},
+			want: "[]int{\n\t1,\n\t2,\n\t3,\n}",
+		},
+		{
+			name: "inner composites fit",
+			fields: fields{
+				Width: 30,
+			},
+			args: args{
+				v: []Obj{{Field: 1}, {Field: 2}},
+			},
+			want: "[]Obj{\n\tObj{Field: 1},\n\tObj{Field: 2},\n}",
+		},
+		{
+			name: "element fits with its comma",
+			fields: fields{
+				Width: 25,
+			},
+			args: args{
+				v: map[string][]int{"a": {1, 2}},
+			},
+			want: "map[string][]int{\n\t\"a\": []int{1, 2},\n}",
+		},
+		{
+			name: "element does not fit with its comma",
+			fields: fields{
+				Width: 24,
+			},
+			args: args{
+				v: map[string][]int{"a": {1, 2}},
+			},
+			want: "map[string][]int{\n\t\"a\": []int{\n\t\t1,\n\t\t2,\n\t},\n}",
+		},
+		{
+			name: "compact",
+			fields: fields{
+				Compact: true,
+			},
+			args: args{
+				v: struct {
+					A int
+					B []string
+				}{A: 1, B: []string{"x"}},
+			},
+			want: "struct{ A int; B []string }{A: 1, B: []string{\"x\"}}",
+		},
+		{
+			name: "aligned map",
+			fields: fields{
+				Align: true,
+			},
+			args: args{
+				v: map[string]int{"a": 1, "bbb": 2},
+			},
+			want: "map[string]int{\n\t\"a\":   1,\n\t\"bbb\": 2,\n}",
+		},
+		{
+			name: "aligned struct with multi-line field",
+			fields: fields{
+				Align: true,
+			},
+			args: args{
+				v: Pair{Key: "k", Value: Obj{}},
+			},
+			want: "Pair{\n\tKey: \"k\",\n\tValue: Obj{\n\t\tField: 0,\n\t},\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Describer{
+				Width:   tt.fields.Width,
+				Compact: tt.fields.Compact,
+				Align:   tt.fields.Align,
+			}
+			if got := d.Value(tt.args.v); got != tt.want {
+				t.Errorf("Describer.Value() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescriber_Type(t *testing.T) {
+	type fields struct {
+		Width   int
+		Compact bool
+		Align   bool
+	}
+	type args struct {
+		v interface{}
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   string
+	}{
+		{
+			name: "zero describer",
+			args: args{
+				v: struct{ A int }{},
+			},
+			want: "struct {\n\tA int\n}",
+		},
+		{
+			name: "struct fits",
+			fields: fields{
+				Width: 40,
+			},
+			args: args{
+				v: struct {
+					A int
+					B string
+				}{},
+			},
+			want: "struct{ A int; B string }",
+		},
+		{
+			name: "compact interface",
+			fields: fields{
+				Compact: true,
+			},
+			args: args{
+				v: func(interface {
+					M()
+					N() int
+				}) {
+				},
+			},
+			want: "func (interface{ M(); N() int })",
+		},
+		{
+			name: "aligned struct",
+			fields: fields{
+				Align: true,
+			},
+			args: args{
+				v: struct {
+					A    int
+					Long string `tag:""`
+				}{},
+			},
+			want: "struct {\n\tA    int\n\tLong string `tag:\"\"`\n}",
+		},
+		{
+			name: "aligned struct fits but has two fields",
+			fields: fields{
+				Width: 80,
+				Align: true,
+			},
+			args: args{
+				v: struct {
+					A int
+					B int
+				}{},
+			},
+			want: "struct {\n\tA int\n\tB int\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Describer{
+				Width:   tt.fields.Width,
+				Compact: tt.fields.Compact,
+				Align:   tt.fields.Align,
+			}
+			if got := d.Type(tt.args.v); got != tt.want {
+				t.Errorf("Describer.Type() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescriber_Align(t *testing.T) {
+	type args struct {
+		v interface{}
+	}
+	tests := []struct {
+		name  string
+		width int
+		args  args
+	}{
+		{
+			name: "struct",
+			args: args{
+				v: struct {
+					A          int
+					LongerName string
+					Nested     struct{ X, YY int }
+					M          map[string]int
+					Obj
+					Tagged int                 `json:"t"`
+					Z      struct{ Q, RR int } `json:"z"`
+				}{M: map[string]int{"a": 1, "bbbb": 2}},
+			},
+		},
+		{
+			name: "keys of very different lengths",
+			args: args{
+				v: map[string]int{
+					"a":  1,
+					"bb": 2,
+					"cccccccccccccccccccccccccccccccccccccccccccccccccccccc": 3,
+					"d": 4,
+				},
+			},
+		},
+		{
+			name:  "fitted",
+			width: 40,
+			args: args{
+				v: map[string]Obj{"a": {1}, "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb": {2}, "cc": {3}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Describer{Width: tt.width, Align: true}
+			src := "package p\n\nvar v = " + d.Value(tt.args.v) + "\n\ntype T " + d.Type(tt.args.v) + "\n"
+			want, err := format.Source([]byte(src))
+			if err != nil {
+				t.Fatalf("format.Source() error = %v", err)
+			}
+			if src != string(want) {
+				t.Errorf("Describer.Value() = %v, want %v", src, string(want))
+			}
+		})
+	}
+}
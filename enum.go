@@ -0,0 +1,154 @@
+package describe
+
+import (
+	"fmt"
+	"math/bits"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// RegisterEnum registers the names of the constants of a named integer or string type, so that values
+// equal to one of them are described by the constant's name, qualified as the type is, rather than as a
+// conversion.  The type is given as for RegisterFormatter and the names as a map from the type's values
+// to their names, such as map[State]string{StateIdle: "StateIdle", StateRunning: "StateRunning"}.
+func RegisterEnum(typ interface{}, names interface{}) {
+	t := typeOf(typ)
+	RegisterFormatter(t, enumFormatter(t, names, false))
+}
+
+// RegisterFlags registers the names of the constants of a named integer type whose values are sets of
+// bit flags, so that values are described as the names of the flags that they hold joined with |, as in
+// pkg.FlagA | pkg.FlagB.  Names of several flags, such as ReadWrite for Read | Write, are used in place of
+// the names of the flags that they are made of.  The names are given as for RegisterEnum.
+func RegisterFlags(typ interface{}, names interface{}) {
+	t := typeOf(typ)
+	RegisterFormatter(t, enumFormatter(t, names, true))
+}
+
+// RegisterEnum registers the names of the constants of a type as the RegisterEnum function does, for this
+// Describer only.
+func (d *Describer) RegisterEnum(typ interface{}, names interface{}) {
+	t := typeOf(typ)
+	d.RegisterFormatter(t, enumFormatter(t, names, false))
+}
+
+// RegisterFlags registers the names of the flags of a type as the RegisterFlags function does, for this
+// Describer only.
+func (d *Describer) RegisterFlags(typ interface{}, names interface{}) {
+	t := typeOf(typ)
+	d.RegisterFormatter(t, enumFormatter(t, names, true))
+}
+
+// EnumNames returns the names of constants for RegisterEnum or RegisterFlags from what their String
+// methods return, each following a prefix, so that EnumNames("State", StateIdle, StateRunning) names
+// StateIdle "StateIdle" if its String method returns "Idle".  The map has the type of the values as its
+// key type.
+func EnumNames(prefix string, values ...interface{}) interface{} {
+	if len(values) == 0 {
+		panic("describe: EnumNames needs at least one value")
+	}
+	t := reflect.TypeOf(values[0])
+	m := reflect.MakeMap(reflect.MapOf(t, reflect.TypeOf("")))
+	for _, v := range values {
+		s, ok := v.(fmt.Stringer)
+		if !ok || reflect.TypeOf(v) != t {
+			panic(fmt.Sprintf("describe: EnumNames value %v is not a %s with a String method", v, t))
+		}
+		m.SetMapIndex(reflect.ValueOf(v), reflect.ValueOf(prefix+s.String()))
+	}
+	return m.Interface()
+}
+
+// enumConst is a constant registered with RegisterEnum or RegisterFlags.
+type enumConst struct {
+	value interface{} // the constant as returned by enumKey
+	bits  uint64      // the bits of the constant of an integer type
+	name  string      // the qualified name
+}
+
+// enumFormatter returns the Formatter for a type registered with RegisterEnum or RegisterFlags.
+func enumFormatter(t reflect.Type, names interface{}, flags bool) Formatter {
+	m := reflect.ValueOf(names)
+	if m.Kind() != reflect.Map || m.Type().Key() != t || m.Type().Elem().Kind() != reflect.String {
+		panic(fmt.Sprintf("describe: enum names for %s are a %s, not a map[%s]string", t, m.Type(), t))
+	}
+	if enumKey(reflect.Zero(t)) == nil || flags && enumBits(reflect.Zero(t)) == nil {
+		panic(fmt.Sprintf("describe: %s is not an enum type", t))
+	}
+
+	qual := strings.TrimSuffix(typeName(t), t.Name())
+	var consts []enumConst
+	byValue := map[interface{}]string{}
+	for _, k := range m.MapKeys() {
+		c := enumConst{value: enumKey(k), name: qual + m.MapIndex(k).String()}
+		if flags {
+			c.bits = *enumBits(k)
+		}
+		consts = append(consts, c)
+		byValue[c.value] = c.name
+	}
+	// Flags that are made of several bits, such as ReadWrite = Read | Write, are matched before the
+	// flags that they are made of.
+	sort.Slice(consts, func(i, j int) bool {
+		if a, b := bits.OnesCount64(consts[i].bits), bits.OnesCount64(consts[j].bits); a != b {
+			return a > b
+		}
+		return consts[i].bits < consts[j].bits
+	})
+
+	return func(s *State, v reflect.Value) {
+		if name, ok := byValue[enumKey(v)]; ok {
+			fmt.Fprintf(s, "%s", name)
+			return
+		}
+		if !flags {
+			s.describeKind(v)
+			return
+		}
+		x := *enumBits(v)
+		var parts []string
+		for _, c := range consts {
+			if c.bits != 0 && x&c.bits == c.bits {
+				parts = append(parts, c.name)
+				x &^= c.bits
+			}
+		}
+		if len(parts) == 0 {
+			s.describeKind(v)
+			return
+		}
+		if x != 0 {
+			parts = append(parts, fmt.Sprintf("%#x", x))
+		}
+		fmt.Fprintf(s, "%s", strings.Join(parts, " | "))
+	}
+}
+
+// enumKey returns a value of an enum type as a map key, or nil if the type is not an integer or string
+// type.
+func enumKey(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.String:
+		return v.String()
+	}
+	return nil
+}
+
+// enumBits returns the bits of a value of an integer type, or nil if the type is not one.
+func enumBits(v reflect.Value) *uint64 {
+	var x uint64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x = uint64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x = v.Uint()
+	default:
+		return nil
+	}
+	return &x
+}
@@ -0,0 +1,90 @@
+package describe
+
+import (
+	"testing"
+)
+
+type Phase int
+
+const (
+	PhaseIdle Phase = iota
+	PhaseRunning
+	PhaseStopped
+)
+
+func (s Phase) String() string {
+	return [...]string{"Idle", "Running", "Stopped"}[s]
+}
+
+type Flag uint8
+
+const (
+	FlagRead Flag = 1 << iota
+	FlagWrite
+	FlagExec
+)
+
+type Mode string
+
+func init() {
+	RegisterEnum(Phase(0), EnumNames("Phase", PhaseIdle, PhaseRunning, PhaseStopped))
+	RegisterFlags(Flag(0), map[Flag]string{FlagRead: "FlagRead", FlagWrite: "FlagWrite", FlagExec: "FlagExec"})
+}
+
+func TestRegisterEnum(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{
+			name: "known",
+			v:    PhaseRunning,
+			want: "PhaseRunning",
+		},
+		{
+			name: "unknown",
+			v:    Phase(7),
+			want: "Phase(7)",
+		},
+		{
+			name: "flags",
+			v:    []Flag{FlagRead | FlagExec, FlagWrite, 0, FlagRead | 64},
+			want: "[]Flag{\n\tFlagRead | FlagExec,\n\tFlagWrite,\n\tFlag(0),\n\tFlagRead | 0x40,\n}",
+		},
+		{
+			name: "map keys",
+			v:    map[Phase]int{PhaseStopped: 2, PhaseIdle: 0},
+			want: "map[Phase]int{\n\tPhaseIdle: 0,\n\tPhaseStopped: 2,\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Value(tt.v); got != tt.want {
+				t.Errorf("Value() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescriber_RegisterEnum(t *testing.T) {
+	d := &Describer{}
+	d.RegisterEnum(Mode(""), map[Mode]string{"ro": "ModeReadOnly"})
+	if got, want := d.Value([]Mode{"ro", "rw"}), "[]Mode{\n\tModeReadOnly,\n\tMode(\"rw\"),\n}"; got != want {
+		t.Errorf("Describer.Value() = %q, want %q", got, want)
+	}
+	if got, want := Value(Mode("ro")), "Mode(\"ro\")"; got != want {
+		t.Errorf("Value() = %q, want %q", got, want)
+	}
+}
+
+func TestDescriber_RegisterFlags(t *testing.T) {
+	d := &Describer{}
+	d.RegisterFlags(Flag(0), map[Flag]string{
+		FlagRead: "FlagRead", FlagWrite: "FlagWrite", FlagExec: "FlagExec", FlagRead | FlagWrite: "FlagReadWrite",
+	})
+	v := []Flag{FlagRead | FlagWrite, FlagRead | FlagWrite | FlagExec, FlagWrite | FlagExec}
+	if got, want := d.Value(v), "[]Flag{\n\tFlagReadWrite,\n\tFlagReadWrite | FlagExec,\n\tFlagWrite | FlagExec,\n}"; got != want {
+		t.Errorf("Describer.Value() = %q, want %q", got, want)
+	}
+}
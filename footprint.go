@@ -0,0 +1,152 @@
+package describe
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// PathSize is the memory that a part of a value allocates, as returned by Sizes.
+type PathSize struct {
+	Path  string // the path to the part, as given to a Visitor
+	Type  reflect.Type
+	Bytes int // the bytes allocated for the part itself, not counting the parts that it refers to
+}
+
+// Size returns the approximate number of bytes of memory that a value retains: the value itself, the
+// values that its pointers refer to, the backing arrays of its slices by capacity, the bytes of its strings
+// and estimates of the buckets of its maps and the buffers of its channels.  Memory that is referred to
+// more than once is counted once.
+func Size(v interface{}) int {
+	n := 0
+	for _, ps := range Sizes(v) {
+		n += ps.Bytes
+	}
+	return n
+}
+
+// Sizes returns the memory that each part of a value allocates, in the order in which the parts are
+// walked, leaving out parts that allocate nothing.  Map keys are parts of their maps.  The memory is
+// counted as Size counts it, and is given to the first part found that refers to it.
+func Sizes(v interface{}) []PathSize {
+	var sizes []PathSize
+	counted := map[sizeKey]bool{}
+	add := func(path string, t reflect.Type, key sizeKey, n int) {
+		if n <= 0 || key.p != 0 && counted[key] {
+			return
+		}
+		if key.p != 0 {
+			counted[key] = true
+		}
+		sizes = append(sizes, PathSize{Path: path, Type: t, Bytes: n})
+	}
+
+	root := true
+	visit := func(path string, t reflect.Type, v reflect.Value) error {
+		if t == nil {
+			return nil
+		}
+		if root {
+			root = false
+			if !pointerShaped(t) {
+				add(path, t, sizeKey{}, int(t.Size()))
+			}
+		}
+		switch t.Kind() {
+		case reflect.Ptr:
+			if v.IsNil() {
+				return nil
+			}
+			key := sizeKey{v.Pointer(), t}
+			if counted[key] {
+				return SkipValue
+			}
+			add(path, t, key, int(t.Elem().Size()))
+		case reflect.Interface:
+			// Values that are not pointers are copied to memory of their own when they are stored in
+			// interfaces.
+			if !v.IsNil() && !pointerShaped(v.Elem().Type()) {
+				add(path, t, sizeKey{}, int(v.Elem().Type().Size()))
+			}
+		case reflect.Slice:
+			if v.IsNil() || v.Cap() == 0 {
+				return nil
+			}
+			key := sizeKey{v.Pointer(), t}
+			if counted[key] {
+				return SkipValue
+			}
+			add(path, t, key, v.Cap()*int(t.Elem().Size()))
+		case reflect.String:
+			// Strings that share their bytes are counted by where the bytes start, whatever their types.
+			s := v.String()
+			add(path, t, sizeKey{uintptr(unsafe.Pointer(unsafe.StringData(s))), stringType}, len(s))
+		case reflect.Map:
+			if v.IsNil() {
+				return nil
+			}
+			key := sizeKey{v.Pointer(), t}
+			if counted[key] {
+				return SkipValue
+			}
+			add(path, t, key, mapSize(t, v.Len()))
+		case reflect.Chan:
+			if v.IsNil() {
+				return nil
+			}
+			add(path, t, sizeKey{v.Pointer(), t}, chanHeader+v.Cap()*int(t.Elem().Size()))
+		}
+		return nil
+	}
+
+	w := walker{p: sizePrinter{}, visit: visit, seen: refs{}}
+	w.walk(reflect.TypeOf(v), reflect.ValueOf(v), "")
+	return sizes
+}
+
+// sizeKey identifies memory counted by Sizes by its address and the type of the value that refers to it,
+// as a struct and its first field have the same address.
+type sizeKey struct {
+	p uintptr
+	t reflect.Type
+}
+
+var stringType = reflect.TypeOf("")
+
+// sizePrinter is the Printer used by Sizes.  It prints nothing and walks everything, including map keys.
+type sizePrinter struct {
+	nopPrinter
+}
+
+func (sizePrinter) Key(i int, k reflect.Value) bool { return true }
+
+// pointerShaped reports whether the values of a type are stored in interfaces as they are, rather than
+// copied to memory of their own.
+func pointerShaped(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return true
+	}
+	return t.Size() == 0
+}
+
+// The sizes of the headers of maps and channels, and of the groups of entries that maps are stored in,
+// which are close enough for an estimate across Go versions.
+const (
+	mapHeader   = 48
+	chanHeader  = 96
+	bucketSize  = 8
+	bucketsLoad = 6.5
+)
+
+// mapSize estimates the memory that a map of n entries allocates: its header and the buckets that hold
+// its entries, each holding up to eight entries with a byte of hash for each and a pointer to an overflow
+// bucket, with as many buckets as keep the entries per bucket below the load factor.
+func mapSize(t reflect.Type, n int) int {
+	buckets := 1
+	for float64(n) > bucketsLoad*float64(buckets) {
+		buckets *= 2
+	}
+	bucket := bucketSize * (1 + int(t.Key().Size()) + int(t.Elem().Size()))
+	bucket += int(reflect.TypeOf(uintptr(0)).Size())
+	return mapHeader + buckets*bucket
+}
@@ -0,0 +1,88 @@
+package describe
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Cache struct {
+	Name    string
+	Entries []int64
+	Primary *Record
+	Backup  *Record
+}
+
+func TestSizes(t *testing.T) {
+	shared := &Record{}
+	text := strings.Repeat("x", 10)
+	cyclic := &Node{}
+	cyclic.Next = cyclic
+	tests := []struct {
+		name string
+		v    interface{}
+		want []PathSize
+	}{
+		{
+			name: "int",
+			v:    1,
+			want: []PathSize{{"", reflect.TypeOf(1), 8}},
+		},
+		{
+			name: "shared pointer",
+			v:    Cache{Name: "users", Entries: make([]int64, 2, 4), Primary: shared, Backup: shared},
+			want: []PathSize{
+				{"", reflect.TypeOf(Cache{}), 56},
+				{".Name", reflect.TypeOf(""), 5},
+				{".Entries", reflect.TypeOf([]int64{}), 32},
+				{".Primary", reflect.TypeOf(shared), 40},
+			},
+		},
+		{
+			name: "shared string",
+			v:    []string{text, text, text[:5]},
+			want: []PathSize{
+				{"", reflect.TypeOf([]string{}), 24},
+				{"", reflect.TypeOf([]string{}), 48},
+				{"[0]", reflect.TypeOf(""), 10},
+			},
+		},
+		{
+			name: "cycle",
+			v:    cyclic,
+			want: []PathSize{{"", reflect.TypeOf(cyclic), int(reflect.TypeOf(Node{}).Size())}},
+		},
+		{
+			name: "map",
+			v:    map[string]bool{"a": true},
+			want: []PathSize{
+				{"", reflect.TypeOf(map[string]bool{}), 48 + 8*(1+16+1) + 8},
+				{"", reflect.TypeOf(""), 1},
+			},
+		},
+		{
+			name: "interface",
+			v:    []interface{}{int64(1), nil},
+			want: []PathSize{
+				{"", reflect.TypeOf([]interface{}{}), 24},
+				{"", reflect.TypeOf([]interface{}{}), 32},
+				{"[0]", reflect.TypeOf((*interface{})(nil)).Elem(), 8},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sizes(tt.v)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sizes() = %v, want %v", got, tt.want)
+			}
+			n := 0
+			for _, ps := range tt.want {
+				n += ps.Bytes
+			}
+			if got := Size(tt.v); got != n {
+				t.Errorf("Size() = %d, want %d", got, n)
+			}
+		})
+	}
+}
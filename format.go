@@ -0,0 +1,288 @@
+package describe
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+)
+
+// A Formatter describes the values of a type in place of the usual description of their kind, such as
+// a call to the function that constructs them.  It writes Go expressions to the State, as with
+// fmt.Fprintf, and may call the State's Describe method for the parts of the value that are to be
+// described in the usual way.
+type Formatter func(s *State, v reflect.Value)
+
+// State is the description being written by a Formatter.
+type State struct {
+	w     *walker
+	level int    // the level of indentation of the value
+	path  string // the path of the value
+	docs  []*doc
+}
+
+// Write writes to the description.  Text should not contain newlines; values that may not fit on a line
+// should be written with Describe so that they are laid out with the rest of the description.
+func (s *State) Write(b []byte) (int, error) {
+	s.w.out.add(len(b))
+	s.docs = append(s.docs, text(string(b)))
+	return len(b), nil
+}
+
+// Describe writes the description of a value as Value would, with the Formatters and options of the
+// description being written.
+func (s *State) Describe(v reflect.Value) {
+	if !v.IsValid() {
+		s.docs = append(s.docs, text("nil"))
+		return
+	}
+	p := &goPrinter{level: s.level, out: s.w.out}
+	w := walker{p: p, d: s.w.d, seen: s.w.seen, safe: s.w.safe, out: s.w.out}
+	w.walk(v.Type(), v, s.path)
+	s.docs = append(s.docs, p.docs...)
+}
+
+// Type writes the name of a type as Value would in a composite literal.
+func (s *State) Type(t reflect.Type) {
+	s.docs = append(s.docs, typeDoc(t, s.level, true))
+}
+
+// Describer returns the Describer writing the description.
+func (s *State) Describer() *Describer {
+	return s.w.d
+}
+
+// registry holds the Formatters and equality functions for types.
+type registry struct {
+	formatters map[reflect.Type]Formatter
+	equals     map[reflect.Type]func(a, b reflect.Value) bool
+}
+
+var formatters struct {
+	sync.RWMutex
+	registry
+}
+
+// RegisterFormatter registers a Formatter for the values of a type, which is used by Value, YAML, TOML,
+// Print and every Describer.  The type is given either by a value of the type, such as (*big.Int)(nil),
+// or by its reflect.Type, which allows interface types.  Printers other than that of Value receive what
+// the Formatter writes through their Text method.
+func RegisterFormatter(typ interface{}, f Formatter) {
+	formatters.Lock()
+	defer formatters.Unlock()
+	formatters.addFormatter(typeOf(typ), f)
+}
+
+// RegisterEqual registers a function that Compare uses to decide whether two values of a type are
+// equal, in place of comparing their descriptions.  The type is given as for RegisterFormatter.
+func RegisterEqual(typ interface{}, eq func(a, b reflect.Value) bool) {
+	formatters.Lock()
+	defer formatters.Unlock()
+	formatters.addEqual(typeOf(typ), eq)
+}
+
+// RegisterFormatter registers a Formatter for the values of a type that is used by this Describer only.
+// It takes precedence over one registered for the type with the RegisterFormatter function.
+func (d *Describer) RegisterFormatter(typ interface{}, f Formatter) {
+	d.registry.addFormatter(typeOf(typ), f)
+}
+
+// RegisterEqual registers a function that the Describer's Compare method uses to decide whether two
+// values of a type are equal.  It takes precedence over one registered with the RegisterEqual function.
+func (d *Describer) RegisterEqual(typ interface{}, eq func(a, b reflect.Value) bool) {
+	d.registry.addEqual(typeOf(typ), eq)
+}
+
+// typeOf returns the type given to the Register functions.
+func typeOf(typ interface{}) reflect.Type {
+	if t, ok := typ.(reflect.Type); ok {
+		return t
+	}
+	return reflect.TypeOf(typ)
+}
+
+func (r *registry) addFormatter(t reflect.Type, f Formatter) {
+	if r.formatters == nil {
+		r.formatters = make(map[reflect.Type]Formatter)
+	}
+	r.formatters[t] = f
+}
+
+func (r *registry) addEqual(t reflect.Type, eq func(a, b reflect.Value) bool) {
+	if r.equals == nil {
+		r.equals = make(map[reflect.Type]func(a, b reflect.Value) bool)
+	}
+	r.equals[t] = eq
+}
+
+// formatter returns the Formatter that describes the values of a type, or nil if they are described by
+// their kind.  Registered Formatters come first, then those for standard library types, then DescribeValue
+// methods and then the methods that the Describer's options select.
+func (d *Describer) formatter(t reflect.Type) Formatter {
+	if f, ok := d.formatters[t]; ok {
+		return f
+	}
+	formatters.RLock()
+	f := formatters.formatters[t]
+	formatters.RUnlock()
+	if f != nil {
+		return f
+	}
+	p := planOf(t)
+	if p.builtin != nil {
+		return p.builtin
+	}
+	if p.self != nil {
+		return p.self
+	}
+	return d.methodFormatter(p)
+}
+
+// equal returns the equality function for a type, or nil if it has none.
+func (d *Describer) equal(t reflect.Type) func(a, b reflect.Value) bool {
+	if eq, ok := d.equals[t]; ok {
+		return eq
+	}
+	formatters.RLock()
+	defer formatters.RUnlock()
+	return formatters.equals[t]
+}
+
+// docPrinter is implemented by Printers that build documents, so that what Formatters write is laid out
+// with the rest of the value.
+type docPrinter interface {
+	addDoc(d *doc)
+	indentLevel() int
+}
+
+// format describes a value with a Formatter.
+func (w *walker) format(t reflect.Type, v reflect.Value, path string, f Formatter) {
+	s := &State{w: w, path: path}
+	dp, ok := w.p.(docPrinter)
+	if ok {
+		s.level = dp.indentLevel()
+	}
+	f(s, v)
+	if ok {
+		dp.addDoc(cat(s.docs...))
+		return
+	}
+	var buf bytes.Buffer
+	layout(&buf, cat(s.docs...), -1, false)
+	w.p.Text(t, buf.String())
+}
+
+// comparison is the state of Compare.  Parts of the first value that have an equality function are
+// recorded by their paths, and parts of the second value that are equal to them are described as they
+// were, so that the descriptions differ only where the values do.
+type comparison struct {
+	record bool
+	parts  map[string]reflect.Value
+}
+
+// match returns the value to describe in place of v.
+func (c *comparison) match(t reflect.Type, v reflect.Value, path string, eq func(a, b reflect.Value) bool) reflect.Value {
+	if c.record {
+		c.parts[path] = v
+		return v
+	}
+	if a, ok := c.parts[path]; ok && a.Type() == t && eq(a, v) {
+		return a
+	}
+	return v
+}
+
+// ValueDescriber is implemented by types that describe their own values, such as to write them as calls
+// to the functions that construct them or to leave out caches.  Value and the Printers call DescribeValue
+// in place of describing the value's fields or elements.  Methods with either value or pointer receivers
+// are called.
+type ValueDescriber interface {
+	DescribeValue(s *State)
+}
+
+// TypeDescriber is implemented by types that describe their own definitions.  Type calls DescribeType on
+// the zero value of the type, or on a pointer to a new zero value if the method has a pointer receiver.
+// Pointers to such types are described as pointers, with the names of the types.
+type TypeDescriber interface {
+	DescribeType(s *State)
+}
+
+var (
+	valueDescriberType = reflect.TypeOf((*ValueDescriber)(nil)).Elem()
+	typeDescriberType  = reflect.TypeOf((*TypeDescriber)(nil)).Elem()
+)
+
+// selfFormatter returns a Formatter that calls the DescribeValue method of a value of type t, or nil if
+// it has none.
+func selfFormatter(t reflect.Type) Formatter {
+	if !hasMethods(t, valueDescriberType) {
+		return nil
+	}
+	return func(s *State, v reflect.Value) {
+		if r, ok := receiver(v, valueDescriberType); ok {
+			r.(ValueDescriber).DescribeValue(s)
+			return
+		}
+		s.describeKind(v)
+	}
+}
+
+// hasMethods reports whether values of type t are described with the methods of the interface type it,
+// which they are if t or pointers to t have them.  Pointers whose element type has them are not, so that
+// the pointer is described as one and the methods are called for the element.
+func hasMethods(t, it reflect.Type) bool {
+	switch {
+	case t.Kind() == reflect.Interface:
+		return false
+	case t.Kind() == reflect.Ptr:
+		return !t.Elem().Implements(it) && t.Implements(it)
+	}
+	return t.Implements(it) || reflect.PtrTo(t).Implements(it)
+}
+
+// receiver returns a value of a type for which hasMethods is true as an interface value with the methods
+// of it, pointing to a copy of the value if only pointers have them.  It returns false for values read
+// from unexported fields, whose methods cannot be called.
+func receiver(v reflect.Value, it reflect.Type) (interface{}, bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+	if v.Type().Implements(it) {
+		return v.Interface(), true
+	}
+	if !v.CanAddr() {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		v = p.Elem()
+	}
+	return v.Addr().Interface(), true
+}
+
+// describeKind describes a value by its kind, without its type's Formatter or DescribeValue method.
+func (s *State) describeKind(v reflect.Value) {
+	// The value is being described already, and is not a cycle.
+	s.w.seen.leave(v)
+	defer s.w.seen.enter(v, s.path)
+	p := &goPrinter{level: s.level, out: s.w.out}
+	w := walker{p: p, d: s.w.d, seen: s.w.seen, kind: true, safe: s.w.safe, out: s.w.out}
+	w.walk(v.Type(), v, s.path)
+	s.docs = append(s.docs, p.docs...)
+}
+
+// selfTypeDoc returns the document written by the DescribeType method of a type, or nil if it has none.
+func selfTypeDoc(t reflect.Type, level int) *doc {
+	var td TypeDescriber
+	switch {
+	case t.Kind() == reflect.Ptr && (t.Elem().Implements(typeDescriberType) || t.Implements(typeDescriberType)):
+		// The pointer is described as one, with the element's name, whichever the receiver of the method.
+		return nil
+	case t.Kind() != reflect.Interface && t.Implements(typeDescriberType):
+		td = reflect.Zero(t).Interface().(TypeDescriber)
+	case t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(typeDescriberType):
+		td = reflect.New(t).Interface().(TypeDescriber)
+	default:
+		return nil
+	}
+	s := &State{w: &walker{d: &Describer{}, seen: refs{}}, level: level}
+	td.DescribeType(s)
+	return cat(s.docs...)
+}
@@ -0,0 +1,215 @@
+package describe
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type Cents int64
+
+type Labeled struct {
+	Label string
+	Value interface{}
+}
+
+type Approx float64
+
+type Reading struct {
+	Sensor string
+	Value  Approx
+}
+
+func init() {
+	RegisterFormatter(Cents(0), func(s *State, v reflect.Value) {
+		fmt.Fprintf(s, "describe.Dollars(%d, %d)", v.Int()/100, v.Int()%100)
+	})
+	RegisterFormatter((*Labeled)(nil), func(s *State, v reflect.Value) {
+		l := v.Elem()
+		fmt.Fprintf(s, "describe.Label(%q, ", l.Field(0).String())
+		s.Describe(l.Field(1))
+		fmt.Fprintf(s, ")")
+	})
+	RegisterEqual(Approx(0), func(a, b reflect.Value) bool {
+		return math.Abs(a.Float()-b.Float()) < 0.01
+	})
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{
+			name: "scalar",
+			v:    Cents(1250),
+			want: "describe.Dollars(12, 50)",
+		},
+		{
+			name: "in a slice",
+			v:    []Cents{5, 100},
+			want: "[]Cents{\n\tdescribe.Dollars(0, 5),\n\tdescribe.Dollars(1, 0),\n}",
+		},
+		{
+			name: "nested description",
+			v:    &Labeled{Label: "total", Value: []Cents{5}},
+			want: "describe.Label(\"total\", []Cents{\n\tdescribe.Dollars(0, 5),\n})",
+		},
+		{
+			name: "nil pointer",
+			v:    (*Labeled)(nil),
+			want: "nil",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Value(tt.v); got != tt.want {
+				t.Errorf("Value() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescriber_RegisterFormatter(t *testing.T) {
+	d := &Describer{Compact: true}
+	d.RegisterFormatter(Cents(0), func(s *State, v reflect.Value) {
+		fmt.Fprintf(s, "%d¢", v.Int())
+	})
+	if got, want := d.Value([]Cents{5}), "[]Cents{5¢}"; got != want {
+		t.Errorf("Describer.Value() = %q, want %q", got, want)
+	}
+	if got, want := Value(Cents(5)), "describe.Dollars(0, 5)"; got != want {
+		t.Errorf("Value() = %q, want %q", got, want)
+	}
+	if got, want := YAML(map[string]Cents{"a": 5}), "a: \"describe.Dollars(0, 5)\" # Cents\n"; got != want {
+		t.Errorf("YAML() = %q, want %q", got, want)
+	}
+}
+
+func TestRegisterEqual(t *testing.T) {
+	DiffFunc(nil)
+	tests := []struct {
+		name string
+		a, b interface{}
+		want bool
+	}{
+		{
+			name: "within tolerance",
+			a:    Reading{Sensor: "t1", Value: 20.001},
+			b:    Reading{Sensor: "t1", Value: 20.002},
+			want: true,
+		},
+		{
+			name: "outside tolerance",
+			a:    Reading{Sensor: "t1", Value: 20},
+			b:    Reading{Sensor: "t1", Value: 21},
+			want: false,
+		},
+		{
+			name: "other field differs",
+			a:    []Reading{{Sensor: "t1", Value: 20.001}},
+			b:    []Reading{{Sensor: "t2", Value: 20.002}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compare(tt.a, tt.b); got != tt.want {
+				t.Errorf("Compare() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type Account struct {
+	Owner string
+	cache map[string]int
+}
+
+func (a Account) DescribeValue(s *State) {
+	fmt.Fprintf(s, "describe.NewAccount(%q)", a.Owner)
+}
+
+func (a Account) DescribeType(s *State) {
+	fmt.Fprintf(s, "struct { Owner string }")
+}
+
+type Buffer struct {
+	Data []byte
+}
+
+func (b *Buffer) DescribeValue(s *State) {
+	fmt.Fprintf(s, "describe.Buffer{ /* %d bytes */ }", len(b.Data))
+}
+
+func TestValueDescriber(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{
+			name: "value receiver",
+			v:    Account{Owner: "bob", cache: map[string]int{"x": 1}},
+			want: "describe.NewAccount(\"bob\")",
+		},
+		{
+			name: "value receiver through pointer",
+			v:    &Account{Owner: "bob"},
+			want: "&describe.NewAccount(\"bob\")",
+		},
+		{
+			name: "pointer receiver",
+			v:    &Buffer{Data: make([]byte, 1024)},
+			want: "describe.Buffer{ /* 1024 bytes */ }",
+		},
+		{
+			name: "pointer receiver on element",
+			v:    []Buffer{{Data: make([]byte, 3)}},
+			want: "[]Buffer{\n\tdescribe.Buffer{ /* 3 bytes */ },\n}",
+		},
+		{
+			name: "pointer receiver on map value",
+			v:    map[string]Buffer{"a": {}},
+			want: "map[string]Buffer{\n\t\"a\": describe.Buffer{ /* 0 bytes */ },\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Value(tt.v); got != tt.want {
+				t.Errorf("Value() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeDescriber(t *testing.T) {
+	if got, want := Type(Account{}), "struct { Owner string }"; got != want {
+		t.Errorf("Type() = %q, want %q", got, want)
+	}
+	if got, want := Type([]Account{}), "[]Account"; got != want {
+		t.Errorf("Type() = %q, want %q", got, want)
+	}
+	if got, want := Type(&Account{}), "*Account"; got != want {
+		t.Errorf("Type() = %q, want %q", got, want)
+	}
+	if got, want := Type(&Ledger{}), "*Ledger"; got != want {
+		t.Errorf("Type() = %q, want %q", got, want)
+	}
+	if got, want := Type(Ledger{}), "struct { Entries []int }"; got != want {
+		t.Errorf("Type() = %q, want %q", got, want)
+	}
+}
+
+type Ledger struct {
+	entries []int
+}
+
+func (l *Ledger) DescribeType(s *State) {
+	if l == nil {
+		panic("DescribeType called on a nil *Ledger")
+	}
+	fmt.Fprintf(s, "struct { Entries []int }")
+}
@@ -0,0 +1,240 @@
+package describe
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Hash returns the SHA-256 hash of the canonical form of a value written by WriteCanonical, which is the
+// same for equal values in every process.
+func Hash(v interface{}) [32]byte {
+	h := sha256.New()
+	WriteCanonical(h, v)
+	var sum [32]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// WriteCanonical writes the canonical form of a value to w, stopping at the first error from w and
+// returning it.  The canonical form is a binary encoding of the value's parts, walked as Walk walks them,
+// in which each part is tagged with its type, map entries are in the order of their encoded keys and
+// references to values that are already being written are written as their paths, so that equal values
+// are written the same way whatever their addresses and the order of their maps.  Channels, functions and
+// unsafe pointers are written as whether or not they are nil.  Formatters are not used.
+func WriteCanonical(w io.Writer, v interface{}) error {
+	bw := bufio.NewWriter(w)
+	wk := walker{seen: refs{}}
+	wk.p = &canonicalPrinter{w: &wk, out: bw}
+	wk.walk(reflect.TypeOf(v), reflect.ValueOf(v), "")
+	if wk.err != nil {
+		return wk.err
+	}
+	return bw.Flush()
+}
+
+// The tags that start the parts of the canonical form.
+const (
+	tagScalar  = 's'
+	tagNil     = 'n'
+	tagRef     = 'r'
+	tagPointer = 'p'
+	tagStruct  = 'S'
+	tagField   = 'f'
+	tagList    = 'L'
+	tagMap     = 'M'
+	tagEnd     = 'e'
+)
+
+// canonicalPrinter is the Printer used by WriteCanonical.
+type canonicalPrinter struct {
+	w   *walker
+	out io.Writer
+	buf []byte
+}
+
+// write writes a tag followed by strings and numbers, each string preceded by its length, reporting any
+// error to the walker.
+func (p *canonicalPrinter) write(tag byte, strs []string, nums ...uint64) {
+	if p.w.err != nil {
+		return
+	}
+	b := append(p.buf[:0], tag)
+	var n [binary.MaxVarintLen64]byte
+	for _, s := range strs {
+		b = append(b, n[:binary.PutUvarint(n[:], uint64(len(s)))]...)
+		b = append(b, s...)
+	}
+	for _, x := range nums {
+		b = append(b, n[:binary.PutUvarint(n[:], x)]...)
+	}
+	p.buf = b
+	if _, err := p.out.Write(b); err != nil {
+		p.w.err = err
+	}
+}
+
+// canonicalType returns the name of a type in the canonical form, qualified by the import path of its
+// package if it is named.
+func canonicalType(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	return t.String()
+}
+
+func (p *canonicalPrinter) Scalar(t reflect.Type, v reflect.Value) {
+	strs := []string{canonicalType(t)}
+	switch t.Kind() {
+	case reflect.Bool:
+		var n uint64
+		if v.Bool() {
+			n = 1
+		}
+		p.write(tagScalar, strs, n)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		p.write(tagScalar, strs, uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		p.write(tagScalar, strs, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		p.write(tagScalar, strs, floatBits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		p.write(tagScalar, strs, floatBits(real(c)), floatBits(imag(c)))
+	case reflect.String:
+		p.write(tagScalar, append(strs, v.String()))
+	default:
+		// The addresses of channels, functions and unsafe pointers differ between processes.
+		var n uint64
+		if !v.IsNil() {
+			n = 1
+		}
+		p.write(tagScalar, strs, n)
+	}
+}
+
+// floatBits returns the bits of a float, with every NaN the same.
+func floatBits(f float64) uint64 {
+	if math.IsNaN(f) {
+		return math.Float64bits(math.NaN())
+	}
+	return math.Float64bits(f)
+}
+
+func (p *canonicalPrinter) Nil(t reflect.Type) {
+	p.write(tagNil, []string{canonicalType(t)})
+}
+
+func (p *canonicalPrinter) Ref(t reflect.Type, path string) {
+	p.write(tagRef, []string{canonicalType(t), path})
+}
+
+func (p *canonicalPrinter) Pointer(t reflect.Type) {
+	p.write(tagPointer, []string{canonicalType(t)})
+}
+
+func (p *canonicalPrinter) BeginStruct(t reflect.Type, v reflect.Value) bool {
+	p.write(tagStruct, []string{canonicalType(t)}, uint64(t.NumField()))
+	return true
+}
+
+func (p *canonicalPrinter) Field(sf reflect.StructField) bool {
+	p.write(tagField, []string{sf.Name})
+	return true
+}
+
+func (p *canonicalPrinter) EndStruct(t reflect.Type) {
+	p.write(tagEnd, nil)
+}
+
+func (p *canonicalPrinter) BeginList(t reflect.Type, v reflect.Value) bool {
+	p.write(tagList, []string{canonicalType(t)}, uint64(v.Len()))
+	return true
+}
+
+func (p *canonicalPrinter) Elem(i int) {}
+
+func (p *canonicalPrinter) EndList(t reflect.Type) {
+	p.write(tagEnd, nil)
+}
+
+// BeginMap writes the entries of a map itself, in the order of their encoded keys, as the order in which
+// the walker finds keys such as pointers depends on their addresses.  Only the keys are encoded before they
+// are written, and each entry is written as its key followed by its value, except that entries whose keys
+// are encoded the same way, such as pointers to equal values and NaNs, are ordered by their encoded values.
+// References within an entry are written as paths from the entry.
+func (p *canonicalPrinter) BeginMap(t reflect.Type, v reflect.Value) bool {
+	p.write(tagMap, []string{canonicalType(t)}, uint64(v.Len()))
+	if p.w.err != nil {
+		return false
+	}
+	encode := func(out io.Writer, t reflect.Type, v reflect.Value) error {
+		w := walker{seen: p.w.seen}
+		w.p = &canonicalPrinter{w: &w, out: out}
+		w.walk(t, v, "")
+		return w.err
+	}
+	encodeString := func(t reflect.Type, v reflect.Value) string {
+		var buf bytes.Buffer
+		encode(&buf, t, v)
+		return buf.String()
+	}
+	type entry struct {
+		key     string
+		value   reflect.Value
+		encoded *string // the encoded value, if the key is shared
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		entries = append(entries, entry{key: encodeString(t.Key(), iter.Key()), value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+	for i := 0; i < len(entries); {
+		j := i + 1
+		for j < len(entries) && entries[j].key == entries[i].key {
+			j++
+		}
+		if j-i > 1 {
+			tied := entries[i:j]
+			for k := range tied {
+				enc := encodeString(t.Elem(), tied[k].value)
+				tied[k].encoded = &enc
+			}
+			sort.Slice(tied, func(a, b int) bool { return *tied[a].encoded < *tied[b].encoded })
+		}
+		i = j
+	}
+	for _, e := range entries {
+		p.write(tagField, []string{e.key})
+		if p.w.err != nil {
+			return false
+		}
+		if e.encoded != nil {
+			if _, err := io.WriteString(p.out, *e.encoded); err != nil {
+				p.w.err = err
+				return false
+			}
+			continue
+		}
+		if err := encode(p.out, t.Elem(), e.value); err != nil {
+			p.w.err = err
+			return false
+		}
+	}
+	p.write(tagEnd, nil)
+	return false
+}
+
+func (p *canonicalPrinter) Key(i int, k reflect.Value) bool { return false }
+func (p *canonicalPrinter) EndMap(t reflect.Type)           {}
+func (p *canonicalPrinter) Text(t reflect.Type, s string)   {}
+func (p *canonicalPrinter) More(n int)                      {}
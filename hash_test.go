@@ -0,0 +1,108 @@
+package describe
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+)
+
+type hashKey struct {
+	A, B int
+}
+
+func TestHash(t *testing.T) {
+	cyclic := &Node{Name: "a"}
+	cyclic.Next = cyclic
+	other := &Node{Name: "a"}
+	other.Next = other
+	tests := []struct {
+		name  string
+		a, b  interface{}
+		equal bool
+	}{
+		{"same ints", 1, 1, true},
+		{"different ints", 1, 2, false},
+		{"different types", int32(1), int64(1), false},
+		{"named type", Cents(1), int64(1), false},
+		{"strings", "ab", "ab", true},
+		{"string boundaries", []string{"a", "b"}, []string{"ab", ""}, false},
+		{"NaN", math.NaN(), math.NaN(), true},
+		{"pointers", &Obj{1}, &Obj{1}, true},
+		{"nil and empty slice", []int(nil), []int{}, true},
+		{"structs", hashKey{1, 2}, hashKey{2, 1}, false},
+		{"struct keys", map[hashKey]int{{1, 2}: 1, {3, 4}: 2}, map[hashKey]int{{3, 4}: 2, {1, 2}: 1}, true},
+		{"pointer keys", map[*Obj]int{{1}: 1, {2}: 2}, map[*Obj]int{{2}: 2, {1}: 1}, true},
+		{"map values", map[string]int{"a": 1}, map[string]int{"a": 2}, false},
+		{"interfaces", []interface{}{1, "a"}, []interface{}{1, "a"}, true},
+		{"interface types", []interface{}{1}, []interface{}{uint(1)}, false},
+		{"cycles", cyclic, other, true},
+		{"nil", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Hash(tt.a) == Hash(tt.b); got != tt.equal {
+				t.Errorf("Hash(%v) == Hash(%v) is %v, want %v", tt.a, tt.b, got, tt.equal)
+			}
+		})
+	}
+}
+
+// failWriter writes n bytes and then fails, counting the writes that fail.
+type failWriter struct {
+	n     int
+	fails int
+}
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if w.n < len(p) {
+		w.fails++
+		return 0, errors.New("full")
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriteCanonical(t *testing.T) {
+	v := map[string][]int{"a": make([]int, 10000)}
+	var buf bytes.Buffer
+	if err := WriteCanonical(&buf, v); err != nil {
+		t.Fatalf("WriteCanonical() = %v", err)
+	}
+	if buf.Len() < 10000 {
+		t.Errorf("WriteCanonical() wrote %d bytes", buf.Len())
+	}
+	if err := WriteCanonical(&failWriter{n: 100}, v); err == nil || err.Error() != "full" {
+		t.Errorf("WriteCanonical() = %v, want full", err)
+	}
+}
+
+func TestWriteCanonical_map(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCanonical(&buf, map[string]int{"b": 2, "a": 1}); err != nil {
+		t.Fatalf("WriteCanonical() = %v", err)
+	}
+	want := "M\x0emap[string]int\x02" +
+		"f\x0as\x06string\x01a" + "s\x03int\x01" +
+		"f\x0as\x06string\x01b" + "s\x03int\x02" +
+		"e"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCanonical() wrote %q, want %q", got, want)
+	}
+}
+
+func TestHash_equalKeys(t *testing.T) {
+	a, b := 1, 1
+	nan := math.NaN()
+	for _, v := range []interface{}{
+		map[*int]string{&a: "x", &b: "y"},
+		map[float64]string{nan: "x", math.NaN(): "y", 1: "z"},
+	} {
+		want := Hash(v)
+		for i := 0; i < 100; i++ {
+			if Hash(v) != want {
+				t.Fatalf("Hash(%v) differs between runs", v)
+			}
+		}
+	}
+}
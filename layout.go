@@ -0,0 +1,555 @@
+package describe
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// Descriptions are built as documents and then laid out to fit a line width, in the manner of Wadler's
+// "A prettier printer".  A document is made of text and breaks, and breaks are collected into groups.
+// When a group is laid out, either all of its breaks become newlines or, if the whole group fits in what
+// remains of the line, none of them do.
+//
+// The elements of composite literals and the fields of struct types are held as rows, which are written
+// one to a line when their group is broken.  When aligning, the cells of the rows are separated with tabs
+// and the lines with newlines or formfeeds as gofmt would, and the output is passed through a tabwriter
+// configured as gofmt configures it.
+
+type docKind int
+
+const (
+	docText  docKind = iota
+	docBreak         // a newline when the group is broken, or text when it is flat
+	docAlt           // text that depends on whether the group is broken
+	docGroup
+	docNest // sets the indentation of the breaks it contains
+	docCat
+	docRows // rows written one to a line at the level of indent when the group is broken
+	docRow  // the cells of a row: a key and a value, or a name, a type and a tag
+)
+
+type doc struct {
+	kind   docKind
+	text   string // the text of text, the flat text of a break or the broken text of an alt
+	flat   string // the flat text of an alt
+	indent int    // the level of a nest or rows
+	fields bool   // whether a group is a struct or interface type and rows and row are its fields
+	docs   []*doc // the contents, or the cells of a row with nil for missing cells
+}
+
+// tabWidth is the width of a level of indentation when fitting lines.
+const tabWidth = 8
+
+func text(s string) *doc {
+	return &doc{kind: docText, text: s}
+}
+
+func textf(format string, args ...interface{}) *doc {
+	return text(fmt.Sprintf(format, args...))
+}
+
+// brk returns a break that is written as flat when its group is flat.
+func brk(flat string) *doc {
+	return &doc{kind: docBreak, text: flat}
+}
+
+func alt(broken, flat string) *doc {
+	return &doc{kind: docAlt, text: broken, flat: flat}
+}
+
+func group(docs ...*doc) *doc {
+	return &doc{kind: docGroup, docs: docs}
+}
+
+// nest returns a document whose breaks are indented to the given level.
+func nest(level int, docs ...*doc) *doc {
+	return &doc{kind: docNest, indent: level, docs: docs}
+}
+
+func cat(docs ...*doc) *doc {
+	return &doc{kind: docCat, docs: docs}
+}
+
+// elemRows returns the elements of a composite literal, each made with elemRow.  Flat, they are separated
+// by commas; broken, each is followed by a comma.
+func elemRows(level int, rows ...*doc) *doc {
+	return &doc{kind: docRows, indent: level, docs: rows}
+}
+
+// elemRow returns an element of a composite literal with an optional key.
+func elemRow(key, value *doc) *doc {
+	return &doc{kind: docRow, docs: []*doc{key, value}}
+}
+
+// fieldRows returns the fields or methods of a struct or interface type, each made with fieldRow.  Flat,
+// they follow a space and are separated by semicolons.
+func fieldRows(level int, rows ...*doc) *doc {
+	return &doc{kind: docRows, indent: level, fields: true, docs: rows}
+}
+
+// fieldRow returns a field of a struct type with an optional name and tag.
+func fieldRow(name, typ, tag *doc) *doc {
+	return &doc{kind: docRow, fields: true, docs: []*doc{name, typ, tag}}
+}
+
+// flatDocs returns the documents that rows or a row are written as when they are flat.
+func flatDocs(d *doc) []*doc {
+	var docs []*doc
+	switch {
+	case d.kind == docRows && d.fields:
+		for i, r := range d.docs {
+			if i > 0 {
+				docs = append(docs, text(";"))
+			}
+			docs = append(docs, text(" "), r)
+		}
+	case d.kind == docRows:
+		for i, r := range d.docs {
+			if i > 0 {
+				docs = append(docs, text(", "))
+			}
+			docs = append(docs, r)
+		}
+	case d.fields:
+		for _, c := range d.docs {
+			if c != nil {
+				if len(docs) > 0 {
+					docs = append(docs, text(" "))
+				}
+				docs = append(docs, c)
+			}
+		}
+	default:
+		if d.docs[0] != nil {
+			docs = append(docs, d.docs[0], text(": "))
+		}
+		docs = append(docs, d.docs[1])
+	}
+	return docs
+}
+
+// hasBreak reports whether a document could be written on more than one line.
+func hasBreak(d *doc) bool {
+	if d == nil {
+		return false
+	}
+	switch d.kind {
+	case docBreak:
+		return true
+	case docRows:
+		return len(d.docs) > 0
+	}
+	for _, c := range d.docs {
+		if hasBreak(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// layoutItem is a document waiting to be laid out.
+type layoutItem struct {
+	indent int
+	flat   bool
+	d      *doc
+}
+
+// layouter holds the state of a layout.
+type layouter struct {
+	f     io.Writer
+	width int
+	align bool
+	col   int
+	lines int   // the number of lines started
+	err   error // the first error from f, after which nothing more is written
+
+	// follow is what follows the document being rendered up to the next newline, which counts when
+	// fitting groups.
+	follow []layoutItem
+}
+
+// layout writes a document, fitting its groups into width columns, and returns the first error from f,
+// at which it stops.  A width of zero breaks every group and a negative width breaks none.  If align is
+// set, rows are aligned in columns as gofmt would.
+func layout(f io.Writer, d *doc, width int, align bool) error {
+	if !align {
+		l := layouter{f: f, width: width}
+		l.render(layoutItem{d: d})
+		return l.err
+	}
+	tw := newTabwriter(f)
+	l := layouter{f: tw, width: width, align: true}
+	l.render(layoutItem{d: d})
+	if l.err != nil {
+		return l.err
+	}
+	return tw.Flush()
+}
+
+// newTabwriter returns a tabwriter configured as gofmt configures it.
+func newTabwriter(f io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(f, 0, tabWidth, 1, ' ', tabwriter.DiscardEmptyColumns|tabwriter.TabIndent|tabwriter.StripEscape)
+}
+
+var escape = string([]byte{tabwriter.Escape})
+
+// out writes a string to f unless there has been an error.
+func (l *layouter) out(s string) {
+	if l.err == nil {
+		_, l.err = io.WriteString(l.f, s)
+	}
+}
+
+func (l *layouter) write(s string) {
+	if l.align && strings.ContainsAny(s, "\t\v\f") {
+		// Escape text that the tabwriter would take for cells.
+		l.out(escape)
+		l.out(s)
+		l.out(escape)
+	} else {
+		l.out(s)
+	}
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		l.lines += strings.Count(s, "\n")
+		l.col = utf8.RuneCountInString(s[i+1:])
+	} else {
+		l.col += utf8.RuneCountInString(s)
+	}
+}
+
+// cell ends a cell and writes the separator that stands for it when not aligning.
+func (l *layouter) cell(sep string) {
+	if l.align {
+		l.out("\v")
+	} else {
+		l.out(sep)
+	}
+	l.col += len(sep)
+}
+
+// newline starts a line at the given level.  A formfeed also ends the alignment of columns.
+func (l *layouter) newline(level int, formfeed bool) {
+	if l.align && formfeed {
+		l.out("\f")
+	} else {
+		l.out("\n")
+	}
+	l.out(indent(level))
+	l.col = level * tabWidth
+	l.lines++
+}
+
+func (l *layouter) render(it layoutItem) {
+	stack := []layoutItem{it}
+	for len(stack) > 0 && l.err == nil {
+		it := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch it.d.kind {
+		case docText:
+			l.write(it.d.text)
+		case docBreak:
+			if it.flat {
+				l.write(it.d.text)
+			} else {
+				l.newline(it.indent, false)
+			}
+		case docAlt:
+			if it.flat {
+				l.write(it.d.flat)
+			} else {
+				l.write(it.d.text)
+			}
+		case docGroup:
+			flat := it.flat
+			switch {
+			case flat:
+			case l.width < 0:
+				flat = true
+			case it.d.fields && l.align && !oneLine(it.d):
+			case l.width > 0:
+				rest := stack
+				if len(l.follow) > 0 {
+					rest = append(append([]layoutItem(nil), l.follow...), stack...)
+				}
+				flat = fits(l.width-l.col, layoutItem{indent: it.indent, flat: true, d: it.d}, rest)
+			}
+			stack = pushDocs(stack, it.indent, flat, it.d.docs)
+		case docNest:
+			stack = pushDocs(stack, it.d.indent, it.flat, it.d.docs)
+		case docCat:
+			stack = pushDocs(stack, it.indent, it.flat, it.d.docs)
+		case docRows:
+			if it.flat {
+				stack = pushDocs(stack, it.indent, true, flatDocs(it.d))
+			} else {
+				l.rows(it.d)
+			}
+		case docRow:
+			stack = pushDocs(stack, it.indent, it.flat, flatDocs(it.d))
+		}
+	}
+}
+
+func pushDocs(stack []layoutItem, indent int, flat bool, docs []*doc) []layoutItem {
+	for i := len(docs) - 1; i >= 0; i-- {
+		if docs[i] != nil {
+			stack = append(stack, layoutItem{indent: indent, flat: flat, d: docs[i]})
+		}
+	}
+	return stack
+}
+
+// oneLine reports whether gofmt would write a struct or interface type on one line, which it does only
+// for a single small field without a tag.
+func oneLine(g *doc) bool {
+	for _, d := range g.docs {
+		if d.kind != docRows {
+			continue
+		}
+		if len(d.docs) != 1 || d.docs[0].docs[2] != nil {
+			return false
+		}
+		size := flatWidth(d.docs[0].docs[1])
+		if d.docs[0].docs[0] != nil {
+			size++
+		}
+		return size <= 30
+	}
+	return true
+}
+
+// singleLine reports whether a row will be written on a single line.
+func (l *layouter) singleLine(r *doc, level int) bool {
+	switch {
+	case l.width < 0 || !hasBreak(r):
+		return true
+	case l.width == 0:
+		return false
+	}
+	// Leave room for the comma that follows the row.
+	return fits(l.width-level*tabWidth-1, layoutItem{indent: level, flat: true, d: r}, nil)
+}
+
+// flatWidth returns the width of a document written flat.
+func flatWidth(d *doc) int {
+	var n runeCounter
+	l := layouter{f: &n, width: -1}
+	l.render(layoutItem{flat: true, d: d})
+	return int(n)
+}
+
+// runeCounter is a Writer that counts the runes written to it.
+type runeCounter int
+
+func (n *runeCounter) Write(p []byte) (int, error) {
+	*n += runeCounter(utf8.RuneCount(p))
+	return len(p), nil
+}
+
+func (n *runeCounter) WriteString(s string) (int, error) {
+	*n += runeCounter(utf8.RuneCountInString(s))
+	return len(s), nil
+}
+
+// rows writes rows one to a line.
+func (l *layouter) rows(d *doc) {
+	follow := l.follow
+	defer func() { l.follow = follow }()
+	l.follow = nil
+	if !d.fields {
+		l.follow = []layoutItem{{d: text(",")}}
+	}
+
+	var rs rowState
+	for _, r := range d.docs {
+		if l.err != nil {
+			return
+		}
+		l.row(&rs, d, r, len(d.docs))
+	}
+	if !d.fields {
+		l.write(",")
+	}
+}
+
+// rowState is what decides where the sections of aligned columns of rows start, as rows are written.  The
+// rules are those of gofmt: the fields of a struct type start a section after a field that spans lines,
+// and the elements of a composite literal start a section at elements that span lines or whose key
+// differs greatly in length from those before it.
+type rowState struct {
+	i       int // the number of rows written
+	size    int // the width of the key of the last row, or zero if it was not on a single line
+	log2sum float64
+	count   int
+	multi   bool // whether the last row spanned lines
+	start   int  // the line on which the row being written started
+}
+
+// row writes a row of rows d, which hold n rows, on a line of its own.
+func (l *layouter) row(rs *rowState, d, r *doc, n int) {
+	single := l.singleLine(r, d.indent)
+	l.startRow(rs, d, r, single)
+	cells := r.docs
+	switch {
+	case d.fields:
+		// Fields are written in columns unless there is only one.
+		sep := l.write
+		if n > 1 {
+			sep = l.cell
+		}
+		if cells[0] != nil {
+			l.render(layoutItem{indent: d.indent, d: cells[0]})
+			sep(" ")
+		}
+		typeStart := l.lines
+		l.render(layoutItem{indent: d.indent, d: cells[1]})
+		if cells[2] != nil {
+			if l.lines > typeStart {
+				// gofmt does not align the tag of a field whose type spans lines.
+				sep = l.write
+			} else if cells[0] != nil && n > 1 && l.align {
+				// gofmt leaves an empty column, which the tabwriter discards, between a named
+				// field's type and its tag.
+				l.cell("")
+			}
+			sep(" ")
+			l.render(layoutItem{indent: d.indent, d: cells[2]})
+		}
+	case single:
+		if cells[0] != nil {
+			l.render(layoutItem{indent: d.indent, flat: true, d: cells[0]})
+			l.write(":")
+			if n > 1 {
+				l.cell(" ")
+			} else {
+				l.write(" ")
+			}
+		}
+		l.render(layoutItem{indent: d.indent, flat: true, d: cells[1]})
+	default:
+		if cells[0] != nil {
+			l.render(layoutItem{indent: d.indent, d: cells[0]})
+			l.write(": ")
+		}
+		l.render(layoutItem{indent: d.indent, d: cells[1]})
+	}
+	l.endRow(rs)
+}
+
+// startRow starts the line of a row of rows d, after the comma that ends the row before it.
+func (l *layouter) startRow(rs *rowState, d, r *doc, single bool) {
+	formfeed := rs.i == 0
+	if !d.fields {
+		if rs.i > 0 {
+			l.write(",")
+		}
+
+		prevSize := rs.size
+		rs.size = 0
+		if single {
+			if r.docs[0] != nil {
+				rs.size = flatWidth(r.docs[0])
+			} else {
+				rs.size = flatWidth(r)
+			}
+		}
+		if rs.i > 0 {
+			formfeed = true
+			if prevSize > 0 && rs.size > 0 {
+				const smallSize = 40
+				if rs.count == 0 || prevSize <= smallSize && rs.size <= smallSize {
+					formfeed = false
+				} else {
+					const ratio = 2.5
+					geomean := exp2ish(rs.log2sum / float64(rs.count))
+					r := float64(rs.size) / geomean
+					formfeed = ratio*r <= 1 || ratio <= r
+				}
+			}
+			if formfeed {
+				rs.log2sum = 0
+				rs.count = 0
+			}
+		}
+	} else if rs.multi {
+		formfeed = true
+	}
+
+	l.newline(d.indent, formfeed)
+	rs.start = l.lines
+}
+
+// endRow ends a row started by startRow.
+func (l *layouter) endRow(rs *rowState) {
+	rs.multi = l.lines > rs.start
+	if rs.size > 0 {
+		rs.log2sum += log2ish(float64(rs.size))
+		rs.count++
+	}
+	rs.i++
+}
+
+// log2ish and exp2ish are the approximations that gofmt uses in deciding sections.
+
+func log2ish(x float64) float64 {
+	f, e := math.Frexp(x)
+	return float64(e) + 2*(f-1)
+}
+
+func exp2ish(x float64) float64 {
+	n := math.Floor(x)
+	f := x - n
+	return math.Ldexp(1+f, int(n))
+}
+
+// fits reports whether the item and whatever follows it, up to the next newline, fit in rem columns.
+func fits(rem int, it layoutItem, rest []layoutItem) bool {
+	stack := []layoutItem{it}
+	next := len(rest) - 1
+	for rem >= 0 {
+		if len(stack) == 0 {
+			if next < 0 {
+				return true
+			}
+			stack = append(stack, rest[next])
+			next--
+		}
+		it := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		s := ""
+		switch it.d.kind {
+		case docText:
+			s = it.d.text
+		case docBreak:
+			if !it.flat {
+				return true
+			}
+			s = it.d.text
+		case docAlt:
+			s = it.d.text
+			if it.flat {
+				s = it.d.flat
+			}
+		case docRows, docRow:
+			if !it.flat && it.d.kind == docRows && len(it.d.docs) > 0 {
+				return true
+			}
+			stack = pushDocs(stack, it.indent, it.flat, flatDocs(it.d))
+			continue
+		default:
+			stack = pushDocs(stack, it.indent, it.flat, it.d.docs)
+			continue
+		}
+		if i := strings.IndexByte(s, '\n'); i >= 0 {
+			return rem >= utf8.RuneCountInString(s[:i])
+		}
+		rem -= utf8.RuneCountInString(s)
+	}
+	return false
+}
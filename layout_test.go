@@ -0,0 +1,96 @@
+package describe
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_layout(t *testing.T) {
+	list := func() *doc {
+		return group(text("["), nest(1, brk(""), text("aaaa"), text(","), brk(" "), text("bbbb")), nest(0, brk("")), text("]"))
+	}
+	type args struct {
+		d     *doc
+		width int
+	}
+	tests := []struct {
+		name  string
+		args  args
+		wantF string
+	}{
+		{
+			name: "always break",
+			args: args{
+				d:     list(),
+				width: 0,
+			},
+			wantF: "[\n\taaaa,\n\tbbbb\n]",
+		},
+		{
+			name: "never break",
+			args: args{
+				d:     list(),
+				width: -1,
+			},
+			wantF: "[aaaa, bbbb]",
+		},
+		{
+			name: "fits",
+			args: args{
+				d:     list(),
+				width: 12,
+			},
+			wantF: "[aaaa, bbbb]",
+		},
+		{
+			name: "does not fit",
+			args: args{
+				d:     list(),
+				width: 11,
+			},
+			wantF: "[\n\taaaa,\n\tbbbb\n]",
+		},
+		{
+			name: "alt",
+			args: args{
+				d:     group(text("x"), alt("broken", "flat")),
+				width: -1,
+			},
+			wantF: "xflat",
+		},
+		{
+			name: "text after group must fit",
+			args: args{
+				d:     cat(list(), text("tail")),
+				width: 14,
+			},
+			wantF: "[\n\taaaa,\n\tbbbb\n]tail",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &bytes.Buffer{}
+			layout(f, tt.args.d, tt.args.width, false)
+			if gotF := f.String(); gotF != tt.wantF {
+				t.Errorf("layout() = %q, want %q", gotF, tt.wantF)
+			}
+		})
+	}
+}
+
+func Test_layoutError(t *testing.T) {
+	rows := make([]*doc, 1000)
+	for i := range rows {
+		rows[i] = elemRow(nil, textf("%d", i))
+	}
+	d := group(text("[]int{"), elemRows(1, rows...), nest(0, brk("")), text("}"))
+	for _, align := range []bool{false, true} {
+		f := &failWriter{n: 10}
+		if err := layout(f, d, 0, align); err == nil {
+			t.Errorf("layout(align: %v) to a full writer succeeded", align)
+		}
+		if f.fails != 1 {
+			t.Errorf("layout(align: %v) failed %d writes, want 1", align, f.fails)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package describe
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits bound the size of the descriptions written by a Describer, so that describing a large value by
+// accident does not write a gigantic description.  What is left out is noted in comments.  Zero values
+// are not limits.
+type Limits struct {
+	// Depth is the number of structs, arrays, slices and maps that may be nested.  Those nested more
+	// deeply are written without their contents.
+	Depth int
+
+	// Elements is the number of elements of an array or slice, or entries of a map, that are written.
+	Elements int
+
+	// String is the number of bytes of a string, or of a slice of bytes written as one, that are written.
+	String int
+
+	// Output is the number of bytes of a description that are written.  The walk over the value stops
+	// once its description is known to be longer.
+	Output int
+}
+
+// DebugLimits are limits that suit descriptions written for debugging, as by Debug.
+var DebugLimits = Limits{Depth: 10, Elements: 100, String: 1 << 10, Output: 64 << 10}
+
+// Debug returns a description of a value, as Value does, within DebugLimits and recovering from panics as
+// a Describer with the Safe option does.
+func Debug(v interface{}) string {
+	return (&Describer{Limits: DebugLimits, Safe: true}).Value(v)
+}
+
+// elide describes a composite holding n fields, elements or entries without its contents if it is nested
+// more deeply than the limits allow, and reports whether it did.
+func (w *walker) elide(t reflect.Type, v reflect.Value, path string, n int, what string) bool {
+	if w.d == nil || w.d.Limits.Depth <= 0 || w.depth < w.d.Limits.Depth || n == 0 {
+		return false
+	}
+	w.format(t, v, path, func(s *State, v reflect.Value) {
+		s.Type(t)
+		fmt.Fprintf(s, "{ /* %s */ }", count(n, what))
+	})
+	return true
+}
+
+// limit returns the number of n elements or entries that are walked.
+func (w *walker) limit(n int) int {
+	if w.d == nil || w.d.Limits.Elements <= 0 || n <= w.d.Limits.Elements {
+		return n
+	}
+	return w.d.Limits.Elements
+}
+
+// stringFormatter returns the Formatter for strings longer than the limits allow, or nil if there is no
+// such limit.
+func (w *walker) stringFormatter(t reflect.Type, v reflect.Value) Formatter {
+	max := w.d.Limits.String
+	if max <= 0 || v.Len() <= max {
+		return nil
+	}
+	return func(s *State, v reflect.Value) {
+		str := v.String()
+		lit := strconv.Quote(truncate(str, max) + "…")
+		if t.PkgPath() != "" {
+			lit = typeName(t) + "(" + lit + ")"
+		}
+		fmt.Fprintf(s, "%s /* %s */", lit, size(len(str)))
+	}
+}
+
+// truncate returns at most the first n bytes of a string, without splitting a character.
+func truncate(s string, n int) string {
+	for n > 0 && n < len(s) && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// count returns a count of things, such as "9,990 more elements".
+func count(n int, what string) string {
+	if n == 1 {
+		return "1 " + what
+	}
+	if strings.HasSuffix(what, "y") {
+		return commas(n) + " " + what[:len(what)-1] + "ies"
+	}
+	return commas(n) + " " + what + "s"
+}
+
+// commas writes a count with its thousands separated by commas.
+func commas(n int) string {
+	s := strconv.Itoa(n)
+	out := s[:(len(s)-1)%3+1]
+	for i := len(out); i < len(s); i += 3 {
+		out += "," + s[i:i+3]
+	}
+	return out
+}
+
+// size returns a number of bytes in the largest unit in which it is at least one, such as "1MB" or
+// "1.5KB".
+func size(n int) string {
+	if n < 1<<10 {
+		return count(n, "byte")
+	}
+	units := []string{"KB", "MB", "GB", "TB"}
+	x := float64(n) / (1 << 10)
+	u := 0
+	for x >= 1<<10 && u < len(units)-1 {
+		x /= 1 << 10
+		u++
+	}
+	return strings.TrimSuffix(strconv.FormatFloat(x, 'f', 1, 64), ".0") + units[u]
+}
+
+// errOutputLimit stops a walk whose description is longer than Limits.Output.
+var errOutputLimit = errors.New("describe: output limit reached")
+
+// budget counts the bytes of text of a description as it is built, which are at most the bytes that it
+// is written in, shared by the walkers that describe its parts.  A nil budget is unlimited.
+type budget struct {
+	n   int
+	max int
+}
+
+func (b *budget) add(n int) {
+	if b != nil {
+		b.n += n
+	}
+}
+
+// spent reports whether the description is longer than the limit.
+func (b *budget) spent() bool {
+	return b != nil && b.n > b.max
+}
+
+// limitWriter writes at most max bytes to w and counts the rest.
+type limitWriter struct {
+	w       io.Writer
+	n       int
+	max     int
+	partial bool // whether the walk was stopped, so that more was left out than was counted
+}
+
+func (lw *limitWriter) Write(b []byte) (int, error) {
+	if room := lw.max - lw.n; room > 0 {
+		if room > len(b) {
+			room = len(b)
+		}
+		if _, err := lw.w.Write(b[:room]); err != nil {
+			return 0, err
+		}
+	}
+	lw.n += len(b)
+	return len(b), nil
+}
+
+// close notes how much of the output was left out.
+func (lw *limitWriter) close() {
+	if lw.n <= lw.max {
+		return
+	}
+	if lw.partial {
+		fmt.Fprintf(lw.w, "… /* at least %s more */", size(lw.n-lw.max))
+	} else {
+		fmt.Fprintf(lw.w, "… /* %s more */", size(lw.n-lw.max))
+	}
+}
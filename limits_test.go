@@ -0,0 +1,125 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDescriber_Limits(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits Limits
+		v      interface{}
+		want   string
+	}{
+		{
+			name:   "elements",
+			limits: Limits{Elements: 2},
+			v:      make([]int, 10002),
+			want:   "[]int{\n\t0,\n\t0 /* 10,000 more elements */,\n}",
+		},
+		{
+			name:   "entries",
+			limits: Limits{Elements: 1},
+			v:      map[string]int{"a": 1, "b": 2, "c": 3},
+			want:   "map[string]int{\n\t\"a\": 1 /* 2 more entries */,\n}",
+		},
+		{
+			name:   "depth",
+			limits: Limits{Depth: 1},
+			v:      []Node{{Name: "a", Next: &Node{Name: "b"}}},
+			want:   "[]Node{\n\tNode{ /* 2 fields */ },\n}",
+		},
+		{
+			name:   "depth of empty composites",
+			limits: Limits{Depth: 1},
+			v:      [][]int{{}},
+			want:   "[][]int{\n\t[]int{},\n}",
+		},
+		{
+			name:   "string",
+			limits: Limits{String: 3},
+			v:      []string{"abcdef", "xyz", strings.Repeat("é", 1<<19)},
+			want:   "[]string{\n\t\"abc…\" /* 6 bytes */,\n\t\"xyz\",\n\t\"é…\" /* 1MB */,\n}",
+		},
+		{
+			name:   "output",
+			limits: Limits{Output: 10},
+			v:      []string{"abcdef", "ghijkl"},
+			want:   "[]string{\n… /* 23 bytes more */",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Describer{Limits: tt.limits}
+			if got := d.Value(tt.v); got != tt.want {
+				t.Errorf("Describer.Value() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescriber_Limits_output(t *testing.T) {
+	d := &Describer{Limits: Limits{Output: 20}}
+	walked := 0
+	d.RegisterFormatter(0, func(s *State, v reflect.Value) {
+		walked++
+		fmt.Fprintf(s, "%d", v.Int())
+	})
+	got := d.Value(make([]int, 1e6))
+	if want := "[]int{\n\t0,\n\t0,\n\t0,\n\t… /* at least 75 bytes more */"; got != want {
+		t.Errorf("Describer.Value() = %q, want %q", got, want)
+	}
+	if walked > 21 {
+		t.Errorf("Describer.Value() walked %d elements after the limit", walked-21)
+	}
+}
+
+func TestDescriber_Limits_bytes(t *testing.T) {
+	d := &Describer{Bytes: true, Limits: Limits{String: 2}}
+	if got, want := d.Value([][]byte{[]byte("hello"), {0, 1, 2}}), "[][]uint8{\n\t[]uint8(\"he…\") /* 5 bytes */,\n\t[]uint8{0x00, 0x01, /* 3 bytes */},\n}"; got != want {
+		t.Errorf("Describer.Value() = %q, want %q", got, want)
+	}
+}
+
+func TestDescriber_Print_Limits(t *testing.T) {
+	d := &Describer{Limits: Limits{Elements: 1}}
+	v := map[string][]int{"a": {1, 2, 3}, "b": {4}}
+	var buf strings.Builder
+	d.Print(v, &yamlPrinter{f: &buf})
+	if got, want := buf.String(), "a:\n  - 1\n  # 2 more elements\n# 1 more entry"; got != want {
+		t.Errorf("YAML = %q, want %q", got, want)
+	}
+	buf.Reset()
+	d.Print(v, &tomlPrinter{f: &buf})
+	if got, want := buf.String(), "[\"a\"][0] = 1\n# [\"a\"]: 2 more elements\n# 1 more entry\n"; got != want {
+		t.Errorf("TOML = %q, want %q", got, want)
+	}
+}
+
+func Test_size(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{1, "1 byte"},
+		{1000, "1,000 bytes"},
+		{1 << 10, "1KB"},
+		{1536, "1.5KB"},
+		{1 << 20, "1MB"},
+	}
+	for _, tt := range tests {
+		if got := size(tt.n); got != tt.want {
+			t.Errorf("size(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestDebug(t *testing.T) {
+	got := Debug(make([]int, 1000))
+	if !strings.HasSuffix(got, "0 /* 900 more elements */,\n}") {
+		t.Errorf("Debug() = %q", got)
+	}
+}
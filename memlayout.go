@@ -0,0 +1,150 @@
+package describe
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// StructLayout is the memory layout of a type, as returned by Layout.
+type StructLayout struct {
+	Type    reflect.Type
+	Size    uintptr       // the size of a value, including padding
+	Align   uintptr       // the alignment of a value
+	Padding uintptr       // the bytes of padding between and after the fields
+	Fields  []FieldLayout // the fields of a struct type in declaration order, or nil for other types
+
+	// Optimal is the fields in the order that needs the least padding, and OptimalSize the size of a
+	// value with its fields in that order.
+	Optimal     []FieldLayout
+	OptimalSize uintptr
+}
+
+// FieldLayout is the memory layout of a field of a struct.
+type FieldLayout struct {
+	Name    string
+	Type    reflect.Type
+	Offset  uintptr // the offset of the field from the start of the struct
+	Size    uintptr
+	Align   uintptr // the alignment of the field within a struct
+	Padding uintptr // the bytes of padding that follow the field
+}
+
+// Layout returns the memory layout of the type of a value, or of the type that it points to: the offset,
+// size, alignment and following padding of each field of a struct, and the order of the fields that needs
+// the least padding.
+func Layout(v interface{}) StructLayout {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return StructLayout{}
+	}
+	l := StructLayout{Type: t, Size: t.Size(), Align: uintptr(t.Align()), OptimalSize: t.Size()}
+	if t.Kind() != reflect.Struct {
+		return l
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		l.Fields = append(l.Fields, FieldLayout{
+			Name:   sf.Name,
+			Type:   sf.Type,
+			Offset: sf.Offset,
+			Size:   sf.Type.Size(),
+			Align:  uintptr(sf.Type.FieldAlign()),
+		})
+	}
+	l.Padding = fieldPadding(l.Fields, l.Size)
+
+	// Fields in decreasing order of alignment follow each other without padding, since sizes are
+	// multiples of alignments.  A zero size field at the end of a struct is given a byte so that pointers
+	// to it do not point past the struct, so such fields go first.
+	l.Optimal = append([]FieldLayout(nil), l.Fields...)
+	sort.SliceStable(l.Optimal, func(i, j int) bool {
+		a, b := l.Optimal[i], l.Optimal[j]
+		if (a.Size == 0) != (b.Size == 0) {
+			return a.Size == 0
+		}
+		return a.Align > b.Align
+	})
+	var off uintptr
+	for i := range l.Optimal {
+		f := &l.Optimal[i]
+		off = alignUp(off, f.Align)
+		f.Offset = off
+		off += f.Size
+	}
+	if len(l.Optimal) > 0 && l.Optimal[len(l.Optimal)-1].Size == 0 && off > 0 {
+		off++
+	}
+	l.OptimalSize = alignUp(off, l.Align)
+	fieldPadding(l.Optimal, l.OptimalSize)
+	return l
+}
+
+// fieldPadding sets the padding that follows each field of a struct of a size, and returns the total.
+func fieldPadding(fields []FieldLayout, size uintptr) uintptr {
+	var total uintptr
+	for i := range fields {
+		end := size
+		if i+1 < len(fields) {
+			end = fields[i+1].Offset
+		}
+		fields[i].Padding = end - fields[i].Offset - fields[i].Size
+		total += fields[i].Padding
+	}
+	if len(fields) == 0 {
+		total = size
+	}
+	return total
+}
+
+// alignUp returns an offset rounded up to a multiple of an alignment.
+func alignUp(off, align uintptr) uintptr {
+	if align <= 1 {
+		return off
+	}
+	return (off + align - 1) / align * align
+}
+
+// String returns the layout as the declaration of the type, with the layout of each field in a comment
+// after it, followed by comments with the size of the type and the optimal order of its fields.
+func (l StructLayout) String() string {
+	if l.Type == nil {
+		return "nil"
+	}
+	var buf bytes.Buffer
+	td := typeDoc(l.Type, 0, true)
+	if l.Fields != nil {
+		rows := make([]*doc, len(l.Fields))
+		for i, f := range l.Fields {
+			c := fmt.Sprintf("// offset %d, size %d, align %d", f.Offset, f.Size, f.Align)
+			if f.Padding > 0 {
+				c += fmt.Sprintf(", %s padding", count(int(f.Padding), "byte"))
+			}
+			rows[i] = fieldRow(text(f.Name), typeDoc(f.Type, 1, true), text(c))
+		}
+		td = cat(text("struct {"), fieldRows(1, rows...), nest(0, brk(" ")), text("}"))
+	}
+	if declared(l.Type) {
+		td = cat(textf("type %s ", typeName(l.Type)), td)
+	}
+	layout(&buf, td, 0, true)
+	fmt.Fprintf(&buf, "\n// %s, align %d", count(int(l.Size), "byte"), l.Align)
+	if l.Fields == nil {
+		return buf.String()
+	}
+	fmt.Fprintf(&buf, ", %s padding", count(int(l.Padding), "byte"))
+	if l.OptimalSize < l.Size {
+		names := make([]string, len(l.Optimal))
+		for i, f := range l.Optimal {
+			names[i] = f.Name
+		}
+		fmt.Fprintf(&buf, "\n// Optimal order: %s (%s)", strings.Join(names, ", "), count(int(l.OptimalSize), "byte"))
+	}
+	return buf.String()
+}
@@ -0,0 +1,104 @@
+package describe
+
+import (
+	"testing"
+)
+
+type Record struct {
+	Live  bool
+	ID    int64
+	Kind  uint8
+	Score float64
+	Tag   uint16
+}
+
+type Packed struct {
+	ID   int64
+	Live bool
+}
+
+func TestLayout(t *testing.T) {
+	tests := []struct {
+		name        string
+		v           interface{}
+		size        uintptr
+		padding     uintptr
+		optimal     []string
+		optimalSize uintptr
+		want        string
+	}{
+		{
+			name:        "padded",
+			v:           Record{},
+			size:        40,
+			padding:     20,
+			optimal:     []string{"ID", "Score", "Tag", "Live", "Kind"},
+			optimalSize: 24,
+			want: "type Record struct {\n" +
+				"\tLive  bool    // offset 0, size 1, align 1, 7 bytes padding\n" +
+				"\tID    int64   // offset 8, size 8, align 8\n" +
+				"\tKind  uint8   // offset 16, size 1, align 1, 7 bytes padding\n" +
+				"\tScore float64 // offset 24, size 8, align 8\n" +
+				"\tTag   uint16  // offset 32, size 2, align 2, 6 bytes padding\n" +
+				"}\n" +
+				"// 40 bytes, align 8, 20 bytes padding\n" +
+				"// Optimal order: ID, Score, Tag, Live, Kind (24 bytes)",
+		},
+		{
+			name:        "pointer to optimal",
+			v:           &Packed{},
+			size:        16,
+			padding:     7,
+			optimal:     []string{"ID", "Live"},
+			optimalSize: 16,
+			want: "type Packed struct {\n" +
+				"\tID   int64 // offset 0, size 8, align 8\n" +
+				"\tLive bool  // offset 8, size 1, align 1, 7 bytes padding\n" +
+				"}\n" +
+				"// 16 bytes, align 8, 7 bytes padding",
+		},
+		{
+			name: "zero size field",
+			v: struct {
+				A int32
+				B struct{}
+			}{},
+			size:        8,
+			padding:     4,
+			optimal:     []string{"B", "A"},
+			optimalSize: 4,
+		},
+		{
+			name:        "not a struct",
+			v:           int32(0),
+			size:        4,
+			optimalSize: 4,
+			want:        "int32\n// 4 bytes, align 4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := Layout(tt.v)
+			if l.Size != tt.size || l.Padding != tt.padding || l.OptimalSize != tt.optimalSize {
+				t.Errorf("Layout() size %d, padding %d, optimal size %d, want %d, %d, %d", l.Size, l.Padding,
+					l.OptimalSize, tt.size, tt.padding, tt.optimalSize)
+			}
+			var names []string
+			for _, f := range l.Optimal {
+				names = append(names, f.Name)
+			}
+			if len(names) != len(tt.optimal) {
+				t.Errorf("Layout() optimal order %v, want %v", names, tt.optimal)
+			}
+			for i := range names {
+				if i < len(tt.optimal) && names[i] != tt.optimal[i] {
+					t.Errorf("Layout() optimal order %v, want %v", names, tt.optimal)
+					break
+				}
+			}
+			if got := l.String(); tt.want != "" && got != tt.want {
+				t.Errorf("Layout().String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
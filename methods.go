@@ -0,0 +1,113 @@
+package describe
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MethodPolicy selects how a Describer uses the Error, String and MarshalText methods of values.
+type MethodPolicy int
+
+const (
+	// IgnoreMethods describes values by their kind alone.
+	IgnoreMethods MethodPolicy = iota
+
+	// CommentMethods follows the description of a value with what its method returns in a comment, as
+	// in pkg.Color(2) /* Red */.
+	CommentMethods
+
+	// UseMethods describes values with what their methods return alone, as in Red.  Such descriptions
+	// are not Go.
+	UseMethods
+)
+
+var (
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	goStringerType    = reflect.TypeOf((*fmt.GoStringer)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// methodTypes are the interfaces whose methods a MethodPolicy uses, in order of preference.
+var methodTypes = []reflect.Type{errorType, stringerType, textMarshalerType}
+
+// methodFormatter returns a Formatter that describes values of a type with the methods that the
+// Describer's options select, or nil if there are none.
+func (d *Describer) methodFormatter(p *typePlan) Formatter {
+	if d.GoString && p.goStringer {
+		return func(s *State, v reflect.Value) {
+			str, err := callMethod(v, goStringerType)
+			if err == errUnexported {
+				s.describeKind(v)
+				return
+			}
+			if err != nil {
+				s.describeKind(v)
+				fmt.Fprintf(s, " %s", comment(err.Error()))
+				return
+			}
+			fmt.Fprintf(s, "%s", str)
+		}
+	}
+	if d.Methods == IgnoreMethods {
+		return nil
+	}
+	for _, it := range p.methods {
+		it := it
+		return func(s *State, v reflect.Value) {
+			str, err := callMethod(v, it)
+			switch {
+			case err == errUnexported:
+				s.describeKind(v)
+			case err != nil:
+				s.describeKind(v)
+				fmt.Fprintf(s, " %s", comment(err.Error()))
+			case d.Methods == UseMethods:
+				fmt.Fprintf(s, "%s", str)
+			default:
+				s.describeKind(v)
+				fmt.Fprintf(s, " %s", comment(str))
+			}
+		}
+	}
+	return nil
+}
+
+// errUnexported is returned by callMethod for values read from unexported fields.
+var errUnexported = errors.New("unexported")
+
+// callMethod calls the method of the interface type it on a value, recovering from any panic.
+func callMethod(v reflect.Value, it reflect.Type) (str string, err error) {
+	r, ok := receiver(v, it)
+	if !ok {
+		return "", errUnexported
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	switch it {
+	case goStringerType:
+		return r.(fmt.GoStringer).GoString(), nil
+	case errorType:
+		return r.(error).Error(), nil
+	case stringerType:
+		return r.(fmt.Stringer).String(), nil
+	case textMarshalerType:
+		b, err := r.(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("MarshalText: %v", err)
+		}
+		return string(b), nil
+	}
+	return "", fmt.Errorf("no method")
+}
+
+// comment returns a comment holding some text.
+func comment(s string) string {
+	return "/* " + strings.ReplaceAll(s, "*/", "* /") + " */"
+}
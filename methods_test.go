@@ -0,0 +1,133 @@
+package describe
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type Color int
+
+func (c Color) String() string {
+	return [...]string{"Red", "Green", "Blue"}[c]
+}
+
+type Level int
+
+func (l *Level) String() string {
+	return fmt.Sprintf("level %d", int(*l))
+}
+
+type Failure struct {
+	Code int
+}
+
+func (f Failure) Error() string {
+	return fmt.Sprintf("failure %d", f.Code)
+}
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) GoString() string {
+	return fmt.Sprintf("describe.Pt(%d, %d)", p.X, p.Y)
+}
+
+type Owner struct {
+	Name *string
+}
+
+func (o Owner) String() string {
+	return *o.Name
+}
+
+type Comment string
+
+func (c Comment) MarshalText() ([]byte, error) {
+	if c == "" {
+		return nil, errors.New("empty")
+	}
+	return []byte("c: */" + string(c)), nil
+}
+
+func TestDescriber_Methods(t *testing.T) {
+	tests := []struct {
+		name string
+		d    Describer
+		v    interface{}
+		want string
+	}{
+		{
+			name: "ignored",
+			v:    Color(2),
+			want: "Color(2)",
+		},
+		{
+			name: "comment",
+			d:    Describer{Methods: CommentMethods},
+			v:    Color(2),
+			want: "Color(2) /* Blue */",
+		},
+		{
+			name: "use",
+			d:    Describer{Methods: UseMethods},
+			v:    []Color{0, 1},
+			want: "[]Color{\n\tRed,\n\tGreen,\n}",
+		},
+		{
+			name: "error",
+			d:    Describer{Methods: UseMethods},
+			v:    []error{Failure{Code: 3}},
+			want: "[]error{\n\tfailure 3,\n}",
+		},
+		{
+			name: "pointer receiver",
+			d:    Describer{Methods: CommentMethods, Compact: true},
+			v:    []Level{1},
+			want: "[]Level{Level(1) /* level 1 */}",
+		},
+		{
+			name: "pointer receiver through pointer",
+			d:    Describer{Methods: CommentMethods},
+			v:    func() *Level { l := Level(4); return &l }(),
+			want: "&Level(4) /* level 4 */",
+		},
+		{
+			name: "panic",
+			d:    Describer{Methods: CommentMethods, Compact: true},
+			v:    Owner{},
+			want: "Owner{Name: nil} /* panic: runtime error: invalid memory address or nil pointer dereference */",
+		},
+		{
+			name: "text marshaler",
+			d:    Describer{Methods: CommentMethods},
+			v:    Comment("x"),
+			want: "Comment(\"x\") /* c: * /x */",
+		},
+		{
+			name: "text marshaler error",
+			d:    Describer{Methods: UseMethods},
+			v:    Comment(""),
+			want: "Comment(\"\") /* MarshalText: empty */",
+		},
+		{
+			name: "go stringer ignored",
+			v:    Point{X: 1, Y: 2},
+			want: "Point{\n\tX: 1,\n\tY: 2,\n}",
+		},
+		{
+			name: "go stringer",
+			d:    Describer{GoString: true},
+			v:    []Point{{X: 1, Y: 2}},
+			want: "[]Point{\n\tdescribe.Pt(1, 2),\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.Value(tt.v); got != tt.want {
+				t.Errorf("Describer.Value() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
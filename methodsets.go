@@ -0,0 +1,80 @@
+package describe
+
+import (
+	"reflect"
+	"strings"
+)
+
+// methodSetDocs returns the declarations of the exported methods of a named type and of pointers to it,
+// each with the receiver it is declared with, followed by comments naming those of the given interfaces
+// that the type and pointers to it implement.  A pointer to a named type has the methods of the type.
+func methodSetDocs(t reflect.Type, ifaces []reflect.Type) []*doc {
+	if t.Kind() == reflect.Ptr && t.Name() == "" {
+		t = t.Elem()
+	}
+	if t.Name() == "" || t.Kind() == reflect.Interface {
+		return nil
+	}
+	name := typeName(t)
+	if name == "" {
+		name = t.Name()
+	}
+
+	var docs []*doc
+	pt := reflect.PtrTo(t)
+	for i := 0; i < pt.NumMethod(); i++ {
+		m := pt.Method(i)
+		recv := "*" + name
+		if _, ok := t.MethodByName(m.Name); ok {
+			recv = name
+		}
+		docs = append(docs, cat(textf("func (%s) %s", recv, m.Name), funcParamsDoc(methodFunc(m.Type), 0)))
+	}
+
+	var byValue, byPointer []string
+	for _, it := range ifaces {
+		switch {
+		case t.Implements(it):
+			byValue = append(byValue, typeName(it))
+		case pt.Implements(it):
+			byPointer = append(byPointer, typeName(it))
+		}
+	}
+	if len(byValue) > 0 {
+		docs = append(docs, textf("// %s implements %s.", name, strings.Join(byValue, ", ")))
+	}
+	if len(byPointer) > 0 {
+		docs = append(docs, textf("// *%s implements %s.", name, strings.Join(byPointer, ", ")))
+	}
+	return docs
+}
+
+// methodFunc returns the type of a method without its receiver.
+func methodFunc(mt reflect.Type) reflect.Type {
+	in := make([]reflect.Type, mt.NumIn()-1)
+	for i := range in {
+		in[i] = mt.In(i + 1)
+	}
+	out := make([]reflect.Type, mt.NumOut())
+	for i := range out {
+		out[i] = mt.Out(i)
+	}
+	return reflect.FuncOf(in, out, mt.IsVariadic())
+}
+
+// withMethodSets follows the document of a type with the declarations of its methods, separated by a blank
+// line, if the Describer lists method sets.
+func (d *Describer) withMethodSets(td *doc, t reflect.Type) *doc {
+	if !d.MethodSets || t == nil {
+		return td
+	}
+	docs := methodSetDocs(t, d.Interfaces)
+	if len(docs) == 0 {
+		return td
+	}
+	out := cat(td, text("\n"))
+	for _, md := range docs {
+		out.docs = append(out.docs, text("\n"), md)
+	}
+	return out
+}
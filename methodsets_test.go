@@ -0,0 +1,82 @@
+package describe
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type Counter struct {
+	N int
+}
+
+func (c Counter) String() string { return fmt.Sprint(c.N) }
+
+func (c *Counter) Write(p []byte) (int, error) {
+	c.N += len(p)
+	return len(p), nil
+}
+
+func (c *Counter) Add(n ...int) {}
+
+func (c Counter) unexported() {}
+
+func TestDescriber_MethodSets(t *testing.T) {
+	d := &Describer{
+		MethodSets: true,
+		Interfaces: []reflect.Type{
+			reflect.TypeOf((*fmt.Stringer)(nil)).Elem(),
+			reflect.TypeOf((*io.Writer)(nil)).Elem(),
+			reflect.TypeOf((*io.Reader)(nil)).Elem(),
+		},
+	}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "Type",
+			got:  d.Type(Counter{}),
+			want: "struct {\n\tN int\n}\n\n" +
+				"func (*Counter) Add(...int)\n" +
+				"func (Counter) String() string\n" +
+				"func (*Counter) Write([]uint8) (int, error)\n" +
+				"// Counter implements fmt.Stringer.\n" +
+				"// *Counter implements io.Writer.",
+		},
+		{
+			name: "pointer",
+			got:  d.Type(&Counter{}),
+			want: "*Counter\n\n" +
+				"func (*Counter) Add(...int)\n" +
+				"func (Counter) String() string\n" +
+				"func (*Counter) Write([]uint8) (int, error)\n" +
+				"// Counter implements fmt.Stringer.\n" +
+				"// *Counter implements io.Writer.",
+		},
+		{
+			name: "no methods",
+			got:  d.Type(Item{}),
+			want: "struct {\n\tName string\n\tParent *Item\n\tKind ItemKind\n}",
+		},
+		{
+			name: "TypeDecls",
+			got:  d.TypeDecls([]Counter{}),
+			want: "type Counter struct {\n\tN int\n}\n\n" +
+				"func (*Counter) Add(...int)\n" +
+				"func (Counter) String() string\n" +
+				"func (*Counter) Write([]uint8) (int, error)\n" +
+				"// Counter implements fmt.Stringer.\n" +
+				"// *Counter implements io.Writer.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NumberFormat selects how integers are written.
+type NumberFormat int
+
+const (
+	Decimal NumberFormat = iota // 42
+	Hex                         // 0x2a
+	Octal                       // 0o52
+	Binary                      // 0b101010
+	Char                        // '*', for integers that are valid Unicode code points
+)
+
+// numberFormats are the names of the NumberFormats in describe struct tags.
+var numberFormats = map[string]NumberFormat{
+	"decimal": Decimal,
+	"hex":     Hex,
+	"octal":   Octal,
+	"binary":  Binary,
+	"char":    Char,
+}
+
+// RegisterNumberFormat registers the format in which the values of an integer type are written, such as
+// Octal for os.FileMode.  The type is given as for RegisterFormatter.  It panics if the type is not an
+// integer type.
+func RegisterNumberFormat(typ interface{}, nf NumberFormat) {
+	t := typeOf(typ)
+	RegisterFormatter(t, numberFormatter(t, nf))
+}
+
+// RegisterNumberFormat registers the format in which the values of an integer type are written by this
+// Describer only.
+func (d *Describer) RegisterNumberFormat(typ interface{}, nf NumberFormat) {
+	t := typeOf(typ)
+	d.RegisterFormatter(t, numberFormatter(t, nf))
+}
+
+// numberFormatter returns a Formatter that writes integers of a type in a format.
+func numberFormatter(t reflect.Type, nf NumberFormat) Formatter {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		panic(fmt.Sprintf("describe: %s is not an integer type and has no number format", t))
+	}
+	return func(s *State, v reflect.Value) {
+		s.docs = append(s.docs, text(conversion(v.Type(), formatInt(v, nf))))
+	}
+}
+
+// formatFormatter returns the Formatter for a value of type t that is written in the format selected by the
+// Describer's options or by the describe tag of the struct field that holds it, or nil if it is written
+// as usual.
+func (w *walker) formatFormatter(t reflect.Type) Formatter {
+	nf, tagged := tagNumberFormat(w.tag)
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !tagged && w.d.Runes && t == reflect.TypeOf(rune(0)) {
+			nf, tagged = Char, true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !tagged && w.d.Unsigned != Decimal {
+			nf, tagged = w.d.Unsigned, true
+		}
+	case reflect.Array, reflect.Slice:
+		if t.Elem().Kind() != reflect.Uint8 {
+			return nil
+		}
+		hex := tagged && nf == Hex
+		if w.d.Bytes || hex || tagHas(w.tag, "string") {
+			max := w.d.Limits.String
+			return func(s *State, v reflect.Value) {
+				s.docs = append(s.docs, text(formatBytes(t, v, hex, max)))
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+	if !tagged {
+		return nil
+	}
+	return numberFormatter(t, nf)
+}
+
+// conversion returns the Go expression that converts a number written as an untyped constant to type t.
+// Conversions to int, and to rune from character literals, are left out as they are the default types of
+// such constants.
+func conversion(t reflect.Type, n string) string {
+	if t.PkgPath() != "" {
+		return typeName(t) + "(" + n + ")"
+	}
+	switch {
+	case t.Kind() == reflect.Int:
+		return n
+	case t.Kind() == reflect.Int32 && strings.HasPrefix(n, "'"):
+		return n
+	}
+	return t.Kind().String() + "(" + n + ")"
+}
+
+// formatInt writes the value of an integer in a format.
+func formatInt(v reflect.Value, nf NumberFormat) string {
+	var neg bool
+	var x uint64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		if i < 0 {
+			neg, x = true, uint64(-i)
+		} else {
+			x = uint64(i)
+		}
+	default:
+		x = v.Uint()
+	}
+
+	var s string
+	switch nf {
+	case Hex:
+		s = "0x" + strconv.FormatUint(x, 16)
+	case Octal:
+		s = "0o" + strconv.FormatUint(x, 8)
+	case Binary:
+		s = "0b" + strconv.FormatUint(x, 2)
+	case Char:
+		if !neg && x <= unicode.MaxRune && utf8.ValidRune(rune(x)) {
+			return strconv.QuoteRune(rune(x))
+		}
+		s = strconv.FormatUint(x, 10)
+	default:
+		s = strconv.FormatUint(x, 10)
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// formatBytes writes an array or slice of bytes on a single line.  Slices of printable text are written
+// as conversions of string literals, and anything else as composite literals of hexadecimal bytes.  Only
+// the first max bytes are written if max is positive.
+func formatBytes(t reflect.Type, v reflect.Value, hex bool, max int) string {
+	b := byteSlice(v)
+	n := len(b)
+	if max > 0 && n > max {
+		b = []byte(truncate(string(b), max))
+	}
+	tn := typeName(t)
+	if tn == "" {
+		var buf strings.Builder
+		layout(&buf, typeDoc(t, 0, true), 0, false)
+		tn = buf.String()
+	}
+	if !hex && t.Kind() == reflect.Slice && t.Elem() == reflect.TypeOf(byte(0)) && printable(b) {
+		if v.IsNil() {
+			return tn + "(nil)"
+		}
+		if n > len(b) {
+			return tn + "(" + strconv.Quote(string(b)+"…") + ") /* " + size(n) + " */"
+		}
+		return tn + "(" + strconv.Quote(string(b)) + ")"
+	}
+	if t.Kind() == reflect.Slice && v.IsNil() {
+		return tn + "(nil)"
+	}
+	var buf strings.Builder
+	buf.WriteString(tn + "{")
+	for i, c := range b {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "0x%02x", c)
+	}
+	if n > len(b) {
+		buf.WriteString(", /* " + size(n) + " */")
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// printable reports whether bytes are text that can be read when written as a string literal.
+func printable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && r != '\n' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// tagNumberFormat returns the NumberFormat named in the options of a describe struct tag.
+func tagNumberFormat(tag string) (NumberFormat, bool) {
+	if tag == "" {
+		return Decimal, false
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		if nf, ok := numberFormats[opt]; ok {
+			return nf, true
+		}
+	}
+	return Decimal, false
+}
+
+// tagHas reports whether the options of a describe struct tag include an option.
+func tagHas(tag, opt string) bool {
+	if tag == "" {
+		return false
+	}
+	for _, o := range strings.Split(tag, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
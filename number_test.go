@@ -0,0 +1,86 @@
+package describe
+
+import (
+	"os"
+	"testing"
+)
+
+type Header struct {
+	Magic  uint32 `describe:"hex"`
+	Mask   uint8  `describe:"binary"`
+	Sep    int32  `describe:"char"`
+	Digest [4]byte
+	Name   []byte `describe:"string"`
+	Sum    []byte `describe:"hex"`
+}
+
+func TestDescriber_numbers(t *testing.T) {
+	tests := []struct {
+		name string
+		d    Describer
+		v    interface{}
+		want string
+	}{
+		{
+			name: "unsigned hex",
+			d:    Describer{Unsigned: Hex, Compact: true},
+			v:    []interface{}{uint(255), uint16(16), -3},
+			want: "[]interface{}{uint(0xff), uint16(0x10), -3}",
+		},
+		{
+			name: "runes",
+			d:    Describer{Runes: true, Compact: true},
+			v:    []rune("a\n"),
+			want: "[]int32{'a', '\\n'}",
+		},
+		{
+			name: "text bytes",
+			d:    Describer{Bytes: true},
+			v:    []byte("hello\n"),
+			want: "[]uint8(\"hello\\n\")",
+		},
+		{
+			name: "binary bytes",
+			d:    Describer{Bytes: true},
+			v:    [][]byte{{0x00, 0xff}, nil},
+			want: "[][]uint8{\n\t[]uint8{0x00, 0xff},\n\t[]uint8(nil),\n}",
+		},
+		{
+			name: "byte array",
+			d:    Describer{Bytes: true},
+			v:    [3]byte{'a', 'b', 'c'},
+			want: "[3]uint8{0x61, 0x62, 0x63}",
+		},
+		{
+			name: "tags",
+			d:    Describer{Compact: true},
+			v:    Header{Magic: 0xcafe, Mask: 5, Sep: ',', Digest: [4]byte{1}, Name: []byte("x"), Sum: []byte("y")},
+			want: "Header{Magic: uint32(0xcafe), Mask: uint8(0b101), Sep: ',', Digest: [4]uint8{uint8(1), uint8(0), uint8(0), uint8(0)}, Name: []uint8(\"x\"), Sum: []uint8{0x79}}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.Value(tt.v); got != tt.want {
+				t.Errorf("Describer.Value() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescriber_RegisterNumberFormat(t *testing.T) {
+	d := &Describer{}
+	d.RegisterNumberFormat(os.FileMode(0), Octal)
+	if got, want := d.Value(os.FileMode(0644)), "io/fs.FileMode(0o644)"; got != want {
+		t.Errorf("Describer.Value() = %q, want %q", got, want)
+	}
+	for _, v := range []interface{}{"", 1.5, []byte(nil), Obj{}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Describer.RegisterNumberFormat(%T) did not panic", v)
+				}
+			}()
+			d.RegisterNumberFormat(v, Hex)
+		}()
+	}
+}
@@ -0,0 +1,201 @@
+package describe
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// typePlan holds what describing the values of a type needs to know about the type, which is worked out
+// once for each type and shared by every Describer.  What depends on the options of a Describer is not
+// part of the plan.
+type typePlan struct {
+	name string // the name of the type as written by typeName
+
+	builtin    Formatter      // the Formatter for a standard library type, if any
+	self       Formatter      // the Formatter that calls DescribeValue methods, if any
+	goStringer bool           // whether values are described with GoString methods
+	methods    []reflect.Type // the interfaces of methodTypes that values are described with, in order
+
+	// The fields of a struct type, with their describe tags and the keys that Value writes for them.
+	fields []reflect.StructField
+	tags   []string
+	keys   []*doc
+
+	docOnce sync.Once
+	doc     *doc // the document of the type's name, if it is written on one line at every level
+}
+
+// plans holds the typePlan of each type described so far.
+var plans sync.Map // map[reflect.Type]*typePlan
+
+// planOf returns the typePlan of a type.
+func planOf(t reflect.Type) *typePlan {
+	if p, ok := plans.Load(t); ok {
+		return p.(*typePlan)
+	}
+	p, _ := plans.LoadOrStore(t, newPlan(t))
+	return p.(*typePlan)
+}
+
+func newPlan(t reflect.Type) *typePlan {
+	p := &typePlan{
+		name:       typeName(t),
+		builtin:    builtinFormatters[t],
+		self:       selfFormatter(t),
+		goStringer: hasMethods(t, goStringerType),
+	}
+	for _, it := range methodTypes {
+		if hasMethods(t, it) {
+			p.methods = append(p.methods, it)
+		}
+	}
+	if t.Kind() != reflect.Struct {
+		return p
+	}
+	p.fields = make([]reflect.StructField, t.NumField())
+	p.tags = make([]string, t.NumField())
+	p.keys = make([]*doc, t.NumField())
+	for i := range p.fields {
+		sf := t.Field(i)
+		p.fields[i] = sf
+		p.tags[i] = sf.Tag.Get("describe")
+		switch {
+		case sf.Anonymous:
+		case sf.PkgPath != "" && sf.PkgPath != thisPackage:
+			p.keys[i] = text(sf.PkgPath + "." + sf.Name)
+		default:
+			p.keys[i] = text(sf.Name)
+		}
+	}
+	return p
+}
+
+// typeDoc returns the document of the type's name, which is shared, or nil if it depends on the level.
+func (p *typePlan) typeDoc(t reflect.Type) *doc {
+	p.docOnce.Do(func() {
+		if d := typeDoc(t, 0, true); !hasBreak(d) {
+			p.doc = d
+		}
+	})
+	return p.doc
+}
+
+// thisPackage is the import path of this package, whose types are written unqualified.
+var thisPackage = reflect.TypeOf(packageType(0)).PkgPath()
+
+// buffers holds the buffers that descriptions are written into before they are returned or written out.
+var buffers = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// writers holds the buffered writers that descriptions are written to writers through.
+var writers = sync.Pool{
+	New: func() interface{} { return bufio.NewWriter(nil) },
+}
+
+// bufferWriter returns a writer that buffers what is written to w and stops at the first error from w,
+// and a function that flushes it, returning the first error.  Buffers are written to directly.
+func bufferWriter(w io.Writer) (io.Writer, func() error) {
+	if buf, ok := w.(*bytes.Buffer); ok {
+		return buf, func() error { return nil }
+	}
+	bw := writers.Get().(*bufio.Writer)
+	bw.Reset(w)
+	return bw, func() error {
+		err := bw.Flush()
+		bw.Reset(nil)
+		writers.Put(bw)
+		return err
+	}
+}
+
+// scalars holds the buffers that scalars are written into.
+var scalars = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 64)
+		return &b
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	return buffers.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	// Large buffers are dropped rather than kept for descriptions that are mostly small.
+	if buf.Cap() > 64<<10 {
+		return
+	}
+	buf.Reset()
+	buffers.Put(buf)
+}
+
+// scalarKind reports whether values of a kind are written by appendScalar.
+func scalarKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32,
+		reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.String:
+		return true
+	}
+	return false
+}
+
+// appendScalar appends the Go expression for a value of a kind for which scalarKind is true: a literal,
+// converted to the value's type unless the type is the default type of the literal.
+func appendScalar(b []byte, t reflect.Type, v reflect.Value) []byte {
+	k := t.Kind()
+	name := planOf(t).name
+	if name == "" && k != reflect.Bool && k != reflect.Int && k != reflect.String {
+		name = k.String()
+	}
+	if name != "" {
+		b = append(b, name...)
+		b = append(b, '(')
+	}
+	b = appendBasic(b, k, v)
+	if name != "" {
+		b = append(b, ')')
+	}
+	return b
+}
+
+// appendBasic appends the literal for a value of a kind for which scalarKind is true.
+func appendBasic(b []byte, k reflect.Kind, v reflect.Value) []byte {
+	switch k {
+	case reflect.Bool:
+		return strconv.AppendBool(b, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(b, v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.AppendUint(b, v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.AppendFloat(b, v.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.AppendFloat(b, v.Float(), 'g', -1, 64)
+	case reflect.Complex64, reflect.Complex128:
+		bits := 64
+		if k == reflect.Complex64 {
+			bits = 32
+		}
+		c := v.Complex()
+		b = strconv.AppendFloat(b, real(c), 'g', -1, bits)
+		if imag(c) != 0 {
+			b = append(b, '+')
+			b = strconv.AppendFloat(b, imag(c), 'g', -1, bits)
+			b = append(b, 'i')
+		}
+		return b
+	case reflect.String:
+		// Should probably do some decoding of the string to make special characters visible, but this
+		// is good enough for now.
+		b = append(b, '"')
+		b = append(b, v.String()...)
+		return append(b, '"')
+	}
+	return b
+}
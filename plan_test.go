@@ -0,0 +1,120 @@
+package describe
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+type benchItem struct {
+	ID     int64
+	Name   string
+	Score  float64
+	Active bool
+	Tags   []string
+	Attrs  map[string]int
+	Next   *benchItem
+}
+
+func benchValue() []benchItem {
+	items := make([]benchItem, 100)
+	for i := range items {
+		items[i] = benchItem{
+			ID:     int64(i),
+			Name:   "item",
+			Score:  float64(i) / 3,
+			Active: i%2 == 0,
+			Tags:   []string{"a", "b"},
+			Attrs:  map[string]int{"x": i, "y": -i},
+		}
+	}
+	return items
+}
+
+func TestAppendScalar(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{true, "true"},
+		{-42, "-42"},
+		{int8(-8), "int8(-8)"},
+		{uint64(1 << 63), "uint64(9223372036854775808)"},
+		{float32(0.1), "float32(0.1)"},
+		{1e21, "float64(1e+21)"},
+		{0.000001, "float64(1e-06)"},
+		{123456.0, "float64(123456)"},
+		{complex64(1 + 2i), "complex64(1+2i)"},
+		{complex(1, 0), "complex128(1)"},
+		{"x", `"x"`},
+		{Cents(5), "Cents(5)"},
+		{Foo(3), "Foo(3)"},
+	}
+	for _, tt := range tests {
+		got := string(appendScalar(nil, reflect.TypeOf(tt.v), reflect.ValueOf(tt.v)))
+		if got != tt.want {
+			t.Errorf("appendScalar(%#v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestDescribeScalarAllocs(t *testing.T) {
+	typ, v := reflect.TypeOf(int64(0)), reflect.ValueOf(int64(42))
+	allocs := testing.AllocsPerRun(100, func() {
+		defaultDescriber.describeValue(io.Discard, typ, v, 0)
+	})
+	if allocs != 0 {
+		t.Errorf("describing a scalar allocated %v times", allocs)
+	}
+}
+
+func TestPlanOfConcurrent(t *testing.T) {
+	done := make(chan string)
+	for i := 0; i < 8; i++ {
+		go func() {
+			done <- Value(benchItem{Tags: []string{"a"}})
+		}()
+	}
+	want := <-done
+	for i := 1; i < 8; i++ {
+		if got := <-done; got != want {
+			t.Errorf("Value() = %q, want %q", got, want)
+		}
+	}
+}
+
+func BenchmarkValueScalar(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Value(int64(i))
+	}
+}
+
+func BenchmarkValueStruct(b *testing.B) {
+	v := benchValue()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Value(v)
+	}
+}
+
+func BenchmarkCompactStruct(b *testing.B) {
+	v := benchValue()
+	d := &Describer{Compact: true}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.Value(v)
+	}
+}
+
+func BenchmarkDescribeScalar(b *testing.B) {
+	d := &Describer{}
+	t := reflect.TypeOf(0.0)
+	v := reflect.ValueOf(1.5)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d.describeValue(io.Discard, t, v, 0)
+	}
+}
@@ -0,0 +1,271 @@
+package describe
+
+import (
+	"reflect"
+)
+
+// Printer formats the parts of a value as Print walks over it.  Print handles the reflection: it follows
+// pointers and interfaces, orders map keys, stops at cycles and reads unexported fields, and reports each
+// part of the value to the Printer in the order in which it is found.  Value, YAML and TOML are all
+// implemented as Printers.
+//
+// Values passed to a Printer may have been read from unexported fields, so a Printer should use the kind
+// specific accessors such as Int and String rather than Interface.
+type Printer interface {
+	// Scalar is called for values of the basic kinds and for channels, functions and unsafe pointers.
+	Scalar(t reflect.Type, v reflect.Value)
+
+	// Nil is called for nil pointers and interfaces.  The type is nil if the value has no type.
+	Nil(t reflect.Type)
+
+	// Ref is called in place of a pointer, map or slice that is already being printed further up the
+	// value, with the path at which it is being printed.
+	Ref(t reflect.Type, path string)
+
+	// Pointer is called before the value that a non-nil pointer refers to.
+	Pointer(t reflect.Type)
+
+	// BeginStruct is called before the fields of a struct.  If it returns false the fields are not
+	// walked and EndStruct is not called.
+	BeginStruct(t reflect.Type, v reflect.Value) bool
+
+	// Field is called before each field of a struct.  If it returns false the field's value is not
+	// walked.
+	Field(sf reflect.StructField) bool
+
+	// EndStruct is called after the fields of a struct.
+	EndStruct(t reflect.Type)
+
+	// BeginList is called before the elements of an array or slice.  If it returns false the elements
+	// are not walked and EndList is not called.
+	BeginList(t reflect.Type, v reflect.Value) bool
+
+	// Elem is called before the i'th element of an array or slice, and before the value of the i'th
+	// entry of a map.
+	Elem(i int)
+
+	// EndList is called after the elements of an array or slice.
+	EndList(t reflect.Type)
+
+	// BeginMap is called before the entries of a map.  If it returns false the entries are not walked
+	// and EndMap is not called.
+	BeginMap(t reflect.Type, v reflect.Value) bool
+
+	// Key is called before the key k of the i'th entry of a map.  If it returns false the key is not
+	// walked, which suits Printers that format keys themselves.
+	Key(i int, k reflect.Value) bool
+
+	// EndMap is called after the entries of a map.
+	EndMap(t reflect.Type)
+
+	// Text is called in place of a value that is described by a Formatter, with the Go expression that
+	// the Formatter wrote.
+	Text(t reflect.Type, s string)
+
+	// More is called before EndList or EndMap when n elements or entries have been left out by the
+	// limits of a Describer.
+	More(n int)
+}
+
+// Print walks over a value and reports what it finds to a Printer.
+func Print(v interface{}, p Printer) {
+	(&Describer{}).Print(v, p)
+}
+
+// Print walks over a value as the Print function does, with the Describer's Formatters, redaction rules
+// and limits.
+func (d *Describer) Print(v interface{}, p Printer) {
+	w := walker{p: p, d: d, seen: refs{}}
+	if d.Safe {
+		w.safe = &safety{}
+	}
+	w.walk(reflect.TypeOf(v), reflect.ValueOf(v), "")
+}
+
+// walker holds the state of a walk over a value.
+type walker struct {
+	p      Printer
+	d      *Describer // the options of the description, or nil if nothing is being described
+	visit  Visitor
+	seen   refs
+	cmp    *comparison
+	kind   bool    // whether to describe the next value by its kind alone
+	tag    string  // the describe struct tag of the field being walked
+	redact bool    // whether the value of the field being walked is redacted
+	depth  int     // the number of composites being walked
+	err    error   // the error that stopped the walk
+	safe   *safety // the panics recovered from, or nil if panics are not recovered from
+	out    *budget // the output written so far against Limits.Output, or nil if there is no such limit
+	stream *stream // the layout of the description as the value is walked, or nil
+}
+
+// visitNode calls the Visitor, if any, and reports whether the walk should continue into the value.
+func (w *walker) visitNode(path string, t reflect.Type, v reflect.Value) bool {
+	if w.visit == nil {
+		return true
+	}
+	if err := w.visit(path, t, v); err != nil {
+		if err != SkipValue {
+			w.err = err
+		}
+		return false
+	}
+	return true
+}
+
+func (w *walker) walk(t reflect.Type, v reflect.Value, path string) {
+	if w.err == nil && w.out.spent() {
+		w.err = errOutputLimit
+	}
+	if w.err == nil {
+		w.err = w.stream.failed()
+	}
+	if w.err != nil {
+		return
+	}
+	if w.safe != nil {
+		defer w.recoverNode(t, path, w.save())
+	}
+
+	if t == nil {
+		if w.visitNode(path, t, v) {
+			w.p.Nil(nil)
+		}
+		return
+	}
+
+	k := t.Kind()
+
+	if p, ok := w.seen.enter(v, path); !ok {
+		w.p.Ref(t, p)
+		return
+	}
+	defer w.seen.leave(v)
+
+	if !w.visitNode(path, t, v) {
+		return
+	}
+	if !v.IsValid() || (k == reflect.Ptr || k == reflect.Interface) && v.IsNil() {
+		w.p.Nil(t)
+		return
+	}
+
+	if w.d != nil {
+		if w.cmp != nil {
+			if eq := w.d.equal(t); eq != nil {
+				v = w.cmp.match(t, v, path, eq)
+			}
+		}
+		if f := w.formatter(t, v); f != nil && !w.kind {
+			w.format(t, v, path, f)
+			return
+		}
+		// A pointer described by its kind has its element described by its kind too, as the element
+		// would otherwise be described with the methods of the pointer.
+		w.kind = w.kind && k == reflect.Ptr
+	}
+
+	switch k {
+	case reflect.Ptr:
+		w.p.Pointer(t)
+		w.walk(t.Elem(), v.Elem(), path)
+	case reflect.Interface:
+		w.walk(v.Elem().Type(), v.Elem(), path)
+	case reflect.Array, reflect.Slice:
+		if w.elide(t, v, path, v.Len(), "element") || !w.p.BeginList(t, v) {
+			return
+		}
+		w.depth++
+		n := w.limit(v.Len())
+		sparse := w.sparse(t, v, n)
+		for i := 0; i < n && w.err == nil; i++ {
+			// The last element of a slice is written so that the literal has the slice's length, and the
+			// last element within the limits so that what follows it can be noted.
+			last := t.Kind() == reflect.Slice && i == v.Len()-1 || n < v.Len() && i == n-1
+			if sparse && !last && v.Index(i).IsZero() {
+				continue
+			}
+			w.p.Elem(i)
+			w.walk(t.Elem(), v.Index(i), indexPath(path, i))
+		}
+		w.depth--
+		if n < v.Len() {
+			w.p.More(v.Len() - n)
+		}
+		w.p.EndList(t)
+	case reflect.Map:
+		if w.elide(t, v, path, v.Len(), "entry") || !w.p.BeginMap(t, v) {
+			return
+		}
+		w.depth++
+		keys := sortedKeys(t, v)
+		n := w.limit(len(keys))
+		for i, mk := range keys[:n] {
+			if w.err != nil {
+				break
+			}
+			if w.p.Key(i, mk) {
+				w.walk(t.Key(), mk, path)
+			}
+			w.p.Elem(i)
+			w.walk(t.Elem(), v.MapIndex(mk), keyPath(path, mk))
+		}
+		w.depth--
+		if n < len(keys) {
+			w.p.More(len(keys) - n)
+		}
+		w.p.EndMap(t)
+	case reflect.Struct:
+		if w.elide(t, v, path, t.NumField(), "field") || !w.p.BeginStruct(t, v) {
+			return
+		}
+		w.depth++
+		plan := planOf(t)
+		for i := range plan.fields {
+			if w.err != nil {
+				break
+			}
+			if w.d != nil && w.d.omitField(plan.tags[i], v.Field(i)) {
+				continue
+			}
+			if w.p.Field(plan.fields[i]) {
+				w.walkField(plan.fields[i], plan.tags[i], v.Field(i), path)
+			}
+		}
+		w.depth--
+		w.p.EndStruct(t)
+	default:
+		w.p.Scalar(t, v)
+	}
+}
+
+// walkField walks the value of a struct field, with the field's describe tag applying to the value and its
+// elements.
+func (w *walker) walkField(sf reflect.StructField, tag string, v reflect.Value, path string) {
+	tag, w.tag = w.tag, tag
+	redact := w.redact
+	w.redact = w.d != nil && (tagHas(w.tag, "redact") || w.d.Redact.field(sf.Name))
+	path = fieldPath(path, sf.Name)
+	if w.cmp != nil && tagHas(w.tag, "noncompare") {
+		v = w.cmp.match(sf.Type, v, path, anyEqual)
+	}
+	w.walk(sf.Type, v, path)
+	w.tag, w.redact = tag, redact
+}
+
+// formatter returns the Formatter that describes a value in place of its kind, or nil if there is none: a
+// registered or builtin Formatter, or one for the methods of its type, the number format or string limit
+// that applies to it, or redaction.
+func (w *walker) formatter(t reflect.Type, v reflect.Value) Formatter {
+	f := w.d.formatter(t)
+	if f == nil {
+		f = w.formatFormatter(t)
+	}
+	if f == nil && t.Kind() == reflect.String {
+		f = w.stringFormatter(t, v)
+	}
+	if w.redact || w.d.Redact.value(t, v) {
+		f = w.d.Redact.formatter()
+	}
+	return f
+}
@@ -0,0 +1,128 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// eventPrinter records the calls made to it.
+type eventPrinter struct {
+	events []string
+}
+
+func (p *eventPrinter) add(format string, args ...interface{}) {
+	p.events = append(p.events, fmt.Sprintf(format, args...))
+}
+
+func (p *eventPrinter) Scalar(t reflect.Type, v reflect.Value) { p.add("Scalar %s", basicValue(t, v)) }
+func (p *eventPrinter) Nil(t reflect.Type)                     { p.add("Nil") }
+func (p *eventPrinter) Ref(t reflect.Type, path string)        { p.add("Ref %s", path) }
+func (p *eventPrinter) Pointer(t reflect.Type)                 { p.add("Pointer") }
+
+func (p *eventPrinter) BeginStruct(t reflect.Type, v reflect.Value) bool {
+	p.add("BeginStruct %s", t.Name())
+	return true
+}
+
+func (p *eventPrinter) Field(sf reflect.StructField) bool {
+	p.add("Field %s", sf.Name)
+	return true
+}
+
+func (p *eventPrinter) EndStruct(t reflect.Type) { p.add("EndStruct") }
+
+func (p *eventPrinter) BeginList(t reflect.Type, v reflect.Value) bool {
+	p.add("BeginList %d", v.Len())
+	return v.Len() > 0
+}
+
+func (p *eventPrinter) Elem(i int)             { p.add("Elem %d", i) }
+func (p *eventPrinter) EndList(t reflect.Type) { p.add("EndList") }
+
+func (p *eventPrinter) BeginMap(t reflect.Type, v reflect.Value) bool {
+	p.add("BeginMap %d", v.Len())
+	return true
+}
+
+func (p *eventPrinter) Key(i int, k reflect.Value) bool {
+	p.add("Key %d", i)
+	return true
+}
+
+func (p *eventPrinter) EndMap(t reflect.Type) { p.add("EndMap") }
+
+func (p *eventPrinter) Text(t reflect.Type, s string) { p.add("Text %s", s) }
+
+func (p *eventPrinter) More(n int) { p.add("More %d", n) }
+
+func TestPrint(t *testing.T) {
+	type args struct {
+		v interface{}
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "nil",
+			args: args{
+				v: nil,
+			},
+			want: "Nil",
+		},
+		{
+			name: "scalar",
+			args: args{
+				v: 1,
+			},
+			want: "Scalar 1",
+		},
+		{
+			name: "empty slice",
+			args: args{
+				v: []int{},
+			},
+			want: "BeginList 0",
+		},
+		{
+			name: "slice of interfaces",
+			args: args{
+				v: []interface{}{1, nil},
+			},
+			want: "BeginList 2, Elem 0, Scalar 1, Elem 1, Nil, EndList",
+		},
+		{
+			name: "map",
+			args: args{
+				v: map[string]int{"b": 2, "a": 1},
+			},
+			want: "BeginMap 2, Key 0, Scalar \"a\", Elem 0, Scalar 1, Key 1, Scalar \"b\", Elem 1, Scalar 2, EndMap",
+		},
+		{
+			name: "struct with unexported field",
+			args: args{
+				v: struct{ a int }{a: 3},
+			},
+			want: "BeginStruct , Field a, Scalar 3, EndStruct",
+		},
+		{
+			name: "cycle",
+			args: args{
+				v: cyclicNode(),
+			},
+			want: "Pointer, BeginStruct Node, Field Name, Scalar \"a\", Field Next, Ref , EndStruct",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &eventPrinter{}
+			Print(tt.args.v, p)
+			if got := strings.Join(p.events, ", "); got != tt.want {
+				t.Errorf("Print() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package describe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Redaction holds the rules by which a Describer redacts secrets from descriptions.  A redacted value is
+// written as the zero value of its type followed by a comment that gives its length, or a hash of it, so
+// that Compare still shows whether it differs.  Values of fields with the describe tag option redact are
+// redacted whatever the rules.
+type Redaction struct {
+	// Fields are patterns, as for path.Match, for the names of struct fields whose values are redacted,
+	// such as "Password", "*Token" or "Secret*".  Names are matched without regard to case.
+	Fields []string
+
+	// Types are the types whose values are redacted.
+	Types []reflect.Type
+
+	// Values are patterns for strings that are redacted wherever they appear.
+	Values []*regexp.Regexp
+
+	// Hash writes the first bytes of the SHA-256 hash of a redacted value in its comment in place of its
+	// length, or of its HMAC-SHA-256 with Key if Key is set.  An unkeyed hash of a secret with little
+	// entropy, such as a password or a PIN, can be reversed by hashing guesses, so descriptions that
+	// others may read should be hashed with a key that they do not have.
+	Hash bool
+
+	// Key is the key of the HMACs written by Hash.
+	Key []byte
+}
+
+// DefaultRedaction redacts the values of fields named for passwords, secrets, tokens and keys, and strings
+// that look like JSON web tokens, AWS access key IDs or PEM private keys.
+var DefaultRedaction = &Redaction{
+	Fields: []string{"*password*", "*passwd*", "*secret*", "*token*", "*apikey*", "*api_key*", "*privatekey*",
+		"*credential*", "authorization"},
+	Values: []*regexp.Regexp{
+		regexp.MustCompile(`^eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*$`),
+		regexp.MustCompile(`^(AKIA|ASIA)[A-Z0-9]{16}$`),
+		regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+	},
+}
+
+// field reports whether the values of a struct field are redacted.
+func (r *Redaction) field(name string) bool {
+	if r == nil {
+		return false
+	}
+	name = strings.ToLower(name)
+	for _, p := range r.Fields {
+		if ok, _ := path.Match(strings.ToLower(p), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// value reports whether a value is redacted for its type or, if it is a string, for its contents.
+func (r *Redaction) value(t reflect.Type, v reflect.Value) bool {
+	if r == nil {
+		return false
+	}
+	for _, rt := range r.Types {
+		if rt == t {
+			return true
+		}
+	}
+	if t.Kind() == reflect.String {
+		for _, re := range r.Values {
+			if re.MatchString(v.String()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatter returns the Formatter for redacted values.
+func (r *Redaction) formatter() Formatter {
+	return func(s *State, v reflect.Value) {
+		s.describeKind(reflect.Zero(v.Type()))
+		fmt.Fprintf(s, " %s", comment(r.marker(v)))
+	}
+}
+
+// marker returns the text of the comment that follows a redacted value.
+func (r *Redaction) marker(v reflect.Value) string {
+	if r != nil && r.Hash {
+		var b []byte
+		if v.Kind() == reflect.String {
+			b = []byte(v.String())
+		} else {
+			var buf strings.Builder
+			(&Describer{Compact: true}).describeValue(&buf, v.Type(), v, 0)
+			b = []byte(buf.String())
+		}
+		if r.Key == nil {
+			return fmt.Sprintf("redacted, sha256 %x", sha256.Sum256(b))[:len("redacted, sha256 ")+16]
+		}
+		h := hmac.New(sha256.New, r.Key)
+		h.Write(b)
+		return fmt.Sprintf("redacted, hmac-sha256 %x", h.Sum(nil))[:len("redacted, hmac-sha256 ")+16]
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("redacted, %d bytes", v.Len())
+	case reflect.Array, reflect.Slice, reflect.Map:
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() != reflect.Map {
+			return fmt.Sprintf("redacted, %d bytes", v.Len())
+		}
+		if v.Len() == 1 {
+			return "redacted, 1 element"
+		}
+		return fmt.Sprintf("redacted, %d elements", v.Len())
+	}
+	return "redacted"
+}
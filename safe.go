@@ -0,0 +1,81 @@
+package describe
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// A PanicError reports a panic while describing a part of a value, as returned by ValueE.
+type PanicError struct {
+	Path  string      // the path to the part, as given to a Visitor
+	Value interface{} // the value passed to panic
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("describe: panic at %s: %v", pathString(e.Path), e.Value)
+}
+
+// ValueE returns what Value returns for a value, recovering from panics as a Describer with the Safe
+// option does, together with an error reporting the first part of the value that panicked, if any.
+func ValueE(v interface{}) (string, error) {
+	return defaultDescriber.ValueE(v)
+}
+
+// ValueE returns what Value returns for a value, recovering from panics as with the Safe option, together
+// with an error reporting the first part of the value that panicked, if any.
+func (d *Describer) ValueE(v interface{}) (string, error) {
+	safe := *d
+	safe.Safe = true
+	var buf bytes.Buffer
+	err := safe.describeValue(&buf, reflect.TypeOf(v), reflect.ValueOf(v), 0)
+	return buf.String(), err
+}
+
+// safety records the first panic recovered in a walk over a value in safe mode.  Walkers that describe
+// parts of the value for Formatters share it.
+type safety struct {
+	err *PanicError
+}
+
+// nodeState is the state of a walker that is restored when recovering from a panic in a part of a value.
+type nodeState struct {
+	kind   bool
+	tag    string
+	redact bool
+	depth  int
+	frames int
+}
+
+// unwinder is implemented by Printers that can discard the composites that they were printing when a
+// part of a value panicked.
+type unwinder interface {
+	frameCount() int
+	unwind(n int)
+}
+
+// save returns the state of the walker before it walks a part of a value.
+func (w *walker) save() nodeState {
+	st := nodeState{kind: w.kind, tag: w.tag, redact: w.redact, depth: w.depth}
+	if u, ok := w.p.(unwinder); ok {
+		st.frames = u.frameCount()
+	}
+	return st
+}
+
+// recoverNode recovers from a panic while walking the part of a value at a path, restoring the state of
+// the walker and printing a comment in place of the part.
+func (w *walker) recoverNode(t reflect.Type, path string, st nodeState) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	w.kind, w.tag, w.redact, w.depth = st.kind, st.tag, st.redact, st.depth
+	if u, ok := w.p.(unwinder); ok {
+		u.unwind(st.frames)
+	}
+	if w.safe.err == nil {
+		w.safe.err = &PanicError{Path: path, Value: r}
+	}
+	w.p.Text(t, comment(fmt.Sprintf("panic: %v", r)))
+}
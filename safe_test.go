@@ -0,0 +1,88 @@
+package describe
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Fuse panics when it is described.
+type Fuse int
+
+func (f Fuse) DescribeValue(s *State) {
+	panic("boom")
+}
+
+type Crate struct {
+	A int
+	B Fuse
+	C []Fuse
+	D *Packed
+}
+
+func TestDescriber_Safe(t *testing.T) {
+	crate := Crate{A: 1, C: []Fuse{1}, D: &Packed{ID: 2}}
+	tests := []struct {
+		name    string
+		d       *Describer
+		v       interface{}
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "method",
+			d:       &Describer{Compact: true},
+			v:       crate,
+			want:    "Crate{A: 1, B: /* panic: boom */, C: []Fuse{/* panic: boom */}, D: &Packed{ID: int64(2), Live: false}}",
+			wantErr: "describe: panic at .B: boom",
+		},
+		{
+			name:    "formatter",
+			d:       &Describer{Compact: true},
+			v:       []int{1, 2},
+			want:    "[]int{/* panic: runtime error: integer divide by zero */, /* panic: runtime error: integer divide by zero */}",
+			wantErr: "describe: panic at [0]: runtime error: integer divide by zero",
+		},
+		{
+			name: "no panic",
+			d:    &Describer{},
+			v:    Packed{},
+			want: "Packed{\n\tID: int64(0),\n\tLive: false,\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.name == "formatter" {
+				zero := 0
+				tt.d.RegisterFormatter(0, func(s *State, v reflect.Value) {
+					s.Write([]byte{byte(1 / zero)})
+				})
+			}
+			got, err := tt.d.ValueE(tt.v)
+			if got != tt.want {
+				t.Errorf("ValueE() = %q, want %q", got, tt.want)
+			}
+			if (err != nil || tt.wantErr != "") && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("ValueE() error = %v, want %q", err, tt.wantErr)
+			}
+			tt.d.Safe = true
+			if got := tt.d.Value(tt.v); got != tt.want {
+				t.Errorf("Value() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescriber_Unsafe(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Value() did not panic")
+		}
+	}()
+	Value(Crate{})
+}
+
+func TestDebug_Safe(t *testing.T) {
+	if got, want := Debug(Fuse(1)), "/* panic: boom */"; got != want {
+		t.Errorf("Debug() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+package describe
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ValueStats are statistics about the parts of a value, as returned by Stats.
+type ValueStats struct {
+	Nodes       int                  // the number of parts, including the value itself
+	Kinds       map[reflect.Kind]int // the number of parts of each kind
+	Types       map[reflect.Type]int // the number of parts of each type, which are the distinct types of the parts
+	MaxDepth    int                  // the greatest number of arrays, slices, maps and structs that hold a part
+	NilPointers int                  // the number of nil pointers
+
+	// LongestSlice is the path to the longest slice or array, and LongestSliceLen its length.
+	LongestSlice    string
+	LongestSliceLen int
+
+	// LargestMap is the path to the map with the most entries, and LargestMapLen its length.
+	LargestMap    string
+	LargestMapLen int
+}
+
+// Stats walks over a value as Walk does, and returns statistics about its parts: how many there are of
+// each kind and type, how deeply they are nested, the longest slice, the largest map and the number of nil
+// pointers.  Map keys are counted as parts.  The longest slice and largest map are the first found if
+// there are several of the same length.
+func Stats(v interface{}) ValueStats {
+	s := ValueStats{Kinds: map[reflect.Kind]int{}, Types: map[reflect.Type]int{}, LongestSliceLen: -1, LargestMapLen: -1}
+	p := &statsPrinter{}
+	visit := func(path string, t reflect.Type, v reflect.Value) error {
+		s.Nodes++
+		if t == nil {
+			return nil
+		}
+		s.Kinds[t.Kind()]++
+		s.Types[t]++
+		if p.depth > s.MaxDepth {
+			s.MaxDepth = p.depth
+		}
+		switch t.Kind() {
+		case reflect.Ptr:
+			if v.IsNil() {
+				s.NilPointers++
+			}
+		case reflect.Array, reflect.Slice:
+			if v.Len() > s.LongestSliceLen {
+				s.LongestSlice, s.LongestSliceLen = path, v.Len()
+			}
+		case reflect.Map:
+			if v.Len() > s.LargestMapLen {
+				s.LargestMap, s.LargestMapLen = path, v.Len()
+			}
+		}
+		return nil
+	}
+	w := walker{p: p, visit: visit, seen: refs{}}
+	w.walk(reflect.TypeOf(v), reflect.ValueOf(v), "")
+	if s.LongestSliceLen < 0 {
+		s.LongestSliceLen = 0
+	}
+	if s.LargestMapLen < 0 {
+		s.LargestMapLen = 0
+	}
+	return s
+}
+
+// TypeDecls returns the declarations of the named types of the parts of the value, and of the named types
+// that they refer to, as TypeDecls does for the type of a value.  Unlike TypeDecls, it declares the types of
+// the values held by interfaces.
+func (s ValueStats) TypeDecls() string {
+	types := make([]reflect.Type, 0, len(s.Types))
+	for t := range s.Types {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].String() < types[j].String() })
+	return (&Describer{}).typeDecls(declOrder(types...))
+}
+
+// statsPrinter is the Printer used by Stats.  It prints nothing and walks everything, including map keys,
+// keeping track of the depth of the part being walked.
+type statsPrinter struct {
+	nopPrinter
+	depth int
+}
+
+func (p *statsPrinter) BeginStruct(t reflect.Type, v reflect.Value) bool { p.depth++; return true }
+func (p *statsPrinter) EndStruct(t reflect.Type)                         { p.depth-- }
+func (p *statsPrinter) BeginList(t reflect.Type, v reflect.Value) bool   { p.depth++; return true }
+func (p *statsPrinter) EndList(t reflect.Type)                           { p.depth-- }
+func (p *statsPrinter) BeginMap(t reflect.Type, v reflect.Value) bool    { p.depth++; return true }
+func (p *statsPrinter) Key(i int, k reflect.Value) bool                  { return true }
+func (p *statsPrinter) EndMap(t reflect.Type)                            { p.depth-- }
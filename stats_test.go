@@ -0,0 +1,71 @@
+package describe
+
+import (
+	"reflect"
+	"testing"
+)
+
+type Inventory struct {
+	Items  []Record
+	Index  map[string]int
+	Owner  *Packed
+	Extras interface{}
+}
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		v     interface{}
+		want  ValueStats
+		decls string
+	}{
+		{
+			name: "nil",
+			v:    nil,
+			want: ValueStats{Nodes: 1, Kinds: map[reflect.Kind]int{}, Types: map[reflect.Type]int{}},
+		},
+		{
+			name: "nested",
+			v: Inventory{
+				Items:  []Record{{}},
+				Index:  map[string]int{"a": 1, "b": 2},
+				Extras: []Packed{},
+			},
+			want: ValueStats{
+				Nodes: 16,
+				Kinds: map[reflect.Kind]int{
+					reflect.Struct: 2, reflect.Slice: 2, reflect.Map: 1, reflect.Ptr: 1, reflect.Interface: 1,
+					reflect.Bool: 1, reflect.Int64: 1, reflect.Uint8: 1, reflect.Float64: 1, reflect.Uint16: 1,
+					reflect.String: 2, reflect.Int: 2,
+				},
+				Types: map[reflect.Type]int{
+					reflect.TypeOf(Inventory{}): 1, reflect.TypeOf([]Record{}): 1, reflect.TypeOf(Record{}): 1,
+					reflect.TypeOf(map[string]int{}): 1, reflect.TypeOf((*Packed)(nil)): 1,
+					reflect.TypeOf((*interface{})(nil)).Elem(): 1, reflect.TypeOf([]Packed{}): 1,
+					reflect.TypeOf(false): 1, reflect.TypeOf(int64(0)): 1, reflect.TypeOf(uint8(0)): 1,
+					reflect.TypeOf(0.0): 1, reflect.TypeOf(uint16(0)): 1, reflect.TypeOf(""): 2, reflect.TypeOf(0): 2,
+				},
+				MaxDepth:        3,
+				NilPointers:     1,
+				LongestSlice:    ".Items",
+				LongestSliceLen: 1,
+				LargestMap:      ".Index",
+				LargestMapLen:   2,
+			},
+			decls: "type Packed struct {\n\tID int64\n\tLive bool\n}\n\n" +
+				"type Record struct {\n\tLive bool\n\tID int64\n\tKind uint8\n\tScore float64\n\tTag uint16\n}\n\n" +
+				"type Inventory struct {\n\tItems []Record\n\tIndex map[string]int\n\tOwner *Packed\n\tExtras interface{}\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Stats(tt.v)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Stats() = %+v, want %+v", got, tt.want)
+			}
+			if decls := got.TypeDecls(); decls != tt.decls {
+				t.Errorf("Stats().TypeDecls() = %q, want %q", decls, tt.decls)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package describe
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// builtinFormatters describe the values of standard library types whose fields are unexported or hold
+// encodings, as calls to the functions that construct them.  Formatters registered for these types take
+// precedence.
+var builtinFormatters map[reflect.Type]Formatter
+
+func init() {
+	// The Formatters describe parts of values themselves, so cannot be referred to in the variable's
+	// initializer.
+	builtinFormatters = map[reflect.Type]Formatter{
+		reflect.TypeOf(time.Time{}):                 formatTime,
+		reflect.TypeOf(time.Duration(0)):            formatDuration,
+		reflect.TypeOf((*time.Location)(nil)):       formatLocation,
+		reflect.TypeOf((*big.Int)(nil)):             formatBigInt,
+		reflect.TypeOf((*big.Float)(nil)):           formatBigFloat,
+		reflect.TypeOf((*big.Rat)(nil)):             formatBigRat,
+		reflect.TypeOf(net.IP(nil)):                 formatIP,
+		reflect.TypeOf(netip.Addr{}):                formatNetip("MustParseAddr"),
+		reflect.TypeOf(netip.AddrPort{}):            formatNetip("MustParseAddrPort"),
+		reflect.TypeOf(netip.Prefix{}):              formatNetip("MustParsePrefix"),
+		reflect.TypeOf((*url.URL)(nil)):             formatURL,
+		reflect.TypeOf((*regexp.Regexp)(nil)):       formatRegexp,
+		reflect.TypeOf((*reflect.Type)(nil)).Elem(): formatReflectType,
+		reflect.TypeOf(reflect.TypeOf(0)):           formatReflectType,
+		reflect.TypeOf(reflect.Value{}):             formatReflectValue,
+		reflect.TypeOf(json.RawMessage(nil)):        formatRawMessage,
+		reflect.TypeOf(sql.NullBool{}):              formatNull,
+		reflect.TypeOf(sql.NullByte{}):              formatNull,
+		reflect.TypeOf(sql.NullFloat64{}):           formatNull,
+		reflect.TypeOf(sql.NullInt16{}):             formatNull,
+		reflect.TypeOf(sql.NullInt32{}):             formatNull,
+		reflect.TypeOf(sql.NullInt64{}):             formatNull,
+		reflect.TypeOf(sql.NullString{}):            formatNull,
+		reflect.TypeOf(sql.NullTime{}):              formatNull,
+	}
+}
+
+// std returns the value of a standard library type, or false if it was read from an unexported field.
+func std(v reflect.Value) (interface{}, bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+func formatTime(s *State, v reflect.Value) {
+	x, ok := std(v)
+	if !ok {
+		s.describeKind(v)
+		return
+	}
+	tm := x.(time.Time)
+	if tm.IsZero() {
+		fmt.Fprintf(s, "time.Time{}")
+		return
+	}
+	fmt.Fprintf(s, "time.Date(%d, time.%s, %d, %d, %d, %d, %d, ", tm.Year(), tm.Month(), tm.Day(), tm.Hour(),
+		tm.Minute(), tm.Second(), tm.Nanosecond())
+	writeLocation(s, tm.Location(), tm)
+	fmt.Fprintf(s, ")")
+}
+
+func formatLocation(s *State, v reflect.Value) {
+	x, ok := std(v)
+	if !ok {
+		s.describeKind(v)
+		return
+	}
+	writeLocation(s, x.(*time.Location), time.Now())
+}
+
+// writeLocation writes a location as time.UTC or time.Local, as a call to time.FixedZone if it has a single
+// zone or as a call to time.LoadLocation if it is in the time zone database.  Other locations are written
+// as fixed zones with the zone in effect at a time.
+func writeLocation(s *State, loc *time.Location, at time.Time) {
+	switch loc {
+	case time.UTC:
+		fmt.Fprintf(s, "time.UTC")
+	case time.Local:
+		fmt.Fprintf(s, "time.Local")
+	default:
+		at = at.In(loc)
+		// The zone of a location with a single zone has no bounds.
+		start, end := at.ZoneBounds()
+		if _, err := time.LoadLocation(loc.String()); err == nil && !(start.IsZero() && end.IsZero()) {
+			writeMust(s, "*time.Location", fmt.Sprintf("time.LoadLocation(%q)", loc.String()))
+			return
+		}
+		name, offset := at.Zone()
+		fmt.Fprintf(s, "time.FixedZone(%q, %d)", name, offset)
+	}
+}
+
+// writeMust writes a call to a function that returns a value and an error or a flag, which cannot be a
+// part of a composite literal, as a call to a function literal that returns just the value.
+func writeMust(s *State, typ, call string) {
+	fmt.Fprintf(s, "func() %s { x, _ := %s; return x }()", typ, call)
+}
+
+// durationUnits are the units in which durations are written, largest first.
+var durationUnits = []struct {
+	d    time.Duration
+	name string
+}{
+	{time.Hour, "time.Hour"},
+	{time.Minute, "time.Minute"},
+	{time.Second, "time.Second"},
+	{time.Millisecond, "time.Millisecond"},
+	{time.Microsecond, "time.Microsecond"},
+	{time.Nanosecond, "time.Nanosecond"},
+}
+
+// formatDuration writes a duration as a multiple of the largest unit that it is a whole number of.
+func formatDuration(s *State, v reflect.Value) {
+	d := time.Duration(v.Int())
+	if d == 0 {
+		fmt.Fprintf(s, "0")
+		return
+	}
+	for _, u := range durationUnits {
+		if d%u.d != 0 {
+			continue
+		}
+		switch n := d / u.d; n {
+		case 1:
+			fmt.Fprintf(s, "%s", u.name)
+		case -1:
+			fmt.Fprintf(s, "-%s", u.name)
+		default:
+			fmt.Fprintf(s, "%d*%s", n, u.name)
+		}
+		return
+	}
+}
+
+func formatBigInt(s *State, v reflect.Value) {
+	x, ok := std(v)
+	if !ok {
+		s.describeKind(v)
+		return
+	}
+	n := x.(*big.Int)
+	if n.IsInt64() {
+		fmt.Fprintf(s, "big.NewInt(%d)", n.Int64())
+		return
+	}
+	writeMust(s, "*big.Int", fmt.Sprintf("new(big.Int).SetString(%q, 10)", n.String()))
+}
+
+func formatBigFloat(s *State, v reflect.Value) {
+	x, ok := std(v)
+	if !ok {
+		s.describeKind(v)
+		return
+	}
+	f := x.(*big.Float)
+	if f.IsInf() {
+		fmt.Fprintf(s, "new(big.Float).SetInf(%t)", f.Signbit())
+		return
+	}
+	if g, acc := f.Float64(); acc == big.Exact && f.Prec() <= 53 {
+		fmt.Fprintf(s, "big.NewFloat(%s)", strconv.FormatFloat(g, 'g', -1, 64))
+		return
+	}
+	writeMust(s, "*big.Float", fmt.Sprintf("new(big.Float).SetPrec(%d).SetString(%q)", f.Prec(), f.Text('g', -1)))
+}
+
+func formatBigRat(s *State, v reflect.Value) {
+	x, ok := std(v)
+	if !ok {
+		s.describeKind(v)
+		return
+	}
+	r := x.(*big.Rat)
+	if r.Num().IsInt64() && r.Denom().IsInt64() {
+		fmt.Fprintf(s, "big.NewRat(%d, %d)", r.Num().Int64(), r.Denom().Int64())
+		return
+	}
+	writeMust(s, "*big.Rat", fmt.Sprintf("new(big.Rat).SetString(%q)", r.String()))
+}
+
+func formatIP(s *State, v reflect.Value) {
+	if v.IsNil() {
+		fmt.Fprintf(s, "net.IP(nil)")
+		return
+	}
+	ip := net.IP(byteSlice(v))
+	if len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+		s.describeKind(v)
+		return
+	}
+	fmt.Fprintf(s, "net.ParseIP(%q)", ip.String())
+}
+
+// formatNetip returns a Formatter for a type of the net/netip package that writes a call to the function
+// that parses its text form.
+func formatNetip(parse string) Formatter {
+	return func(s *State, v reflect.Value) {
+		x, ok := std(v)
+		if !ok {
+			s.describeKind(v)
+			return
+		}
+		if v.IsZero() {
+			s.Type(v.Type())
+			fmt.Fprintf(s, "{}")
+			return
+		}
+		fmt.Fprintf(s, "netip.%s(%q)", parse, x)
+	}
+}
+
+func formatURL(s *State, v reflect.Value) {
+	x, ok := std(v)
+	if !ok {
+		s.describeKind(v)
+		return
+	}
+	// The fields that are set are written from the struct, as fields have been added to it in new versions
+	// of Go.
+	fmt.Fprintf(s, "&url.URL{")
+	u := reflect.ValueOf(x).Elem()
+	n := 0
+	for i := 0; i < u.NumField(); i++ {
+		f := u.Field(i)
+		if f.IsZero() || !u.Type().Field(i).IsExported() {
+			continue
+		}
+		if n > 0 {
+			fmt.Fprintf(s, ", ")
+		}
+		n++
+		fmt.Fprintf(s, "%s: ", u.Type().Field(i).Name)
+		switch f := f.Interface().(type) {
+		case *url.Userinfo:
+			if p, ok := f.Password(); ok {
+				// Passwords are always redacted, as by url.URL.Redacted, with the Describer's rules
+				// giving the form of the marker.
+				var r *Redaction
+				if d := s.Describer(); d != nil {
+					r = d.Redact
+				}
+				fmt.Fprintf(s, "url.UserPassword(%q, \"\" %s)", f.Username(), comment(r.marker(reflect.ValueOf(p))))
+			} else {
+				fmt.Fprintf(s, "url.User(%q)", f.Username())
+			}
+		case string:
+			fmt.Fprintf(s, "%q", f)
+		default:
+			fmt.Fprintf(s, "%v", f)
+		}
+	}
+	fmt.Fprintf(s, "}")
+}
+
+func formatRegexp(s *State, v reflect.Value) {
+	x, ok := std(v)
+	if !ok {
+		s.describeKind(v)
+		return
+	}
+	fmt.Fprintf(s, "regexp.MustCompile(%s)", rawQuote(x.(*regexp.Regexp).String()))
+}
+
+func formatReflectType(s *State, v reflect.Value) {
+	x, ok := std(v)
+	if !ok {
+		s.describeKind(v)
+		return
+	}
+	fmt.Fprintf(s, "reflect.TypeOf((*")
+	s.Type(x.(reflect.Type))
+	fmt.Fprintf(s, ")(nil)).Elem()")
+}
+
+func formatReflectValue(s *State, v reflect.Value) {
+	x, ok := std(v)
+	if !ok {
+		s.describeKind(v)
+		return
+	}
+	rv := x.(reflect.Value)
+	if !rv.IsValid() {
+		fmt.Fprintf(s, "reflect.Value{}")
+		return
+	}
+	fmt.Fprintf(s, "reflect.ValueOf(")
+	s.Describe(rv)
+	fmt.Fprintf(s, ")")
+}
+
+func formatRawMessage(s *State, v reflect.Value) {
+	if v.IsNil() {
+		fmt.Fprintf(s, "json.RawMessage(nil)")
+		return
+	}
+	fmt.Fprintf(s, "json.RawMessage(%s)", rawQuote(string(v.Bytes())))
+}
+
+// formatNull writes a value of one of the sql.Null types on a single line, leaving out the value if it is
+// not valid.
+func formatNull(s *State, v reflect.Value) {
+	s.Type(v.Type())
+	if !v.FieldByName("Valid").Bool() {
+		fmt.Fprintf(s, "{}")
+		return
+	}
+	fmt.Fprintf(s, "{%s: ", v.Type().Field(0).Name)
+	s.Describe(v.Field(0))
+	fmt.Fprintf(s, ", Valid: true}")
+}
+
+// rawQuote returns a string as a raw string literal if it can be written as one, and otherwise as an
+// interpreted string literal.
+func rawQuote(str string) string {
+	if strconv.CanBackquote(str) && !strings.Contains(str, "\n") {
+		return "`" + str + "`"
+	}
+	return strconv.Quote(str)
+}
@@ -0,0 +1,192 @@
+package describe
+
+import (
+	"io"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// Values are described as they are walked rather than built as whole documents and then laid out, so that
+// large values are written without being held in memory.  The goPrinter holds a composite as a document
+// only until it is known how its group is laid out: until it is wider than a line, so that it is broken,
+// or at once when every group is broken or none is.  The composite is then opened: its type and brace are
+// written, and its rows are written one at a time as they are completed, the last of them after any
+// composite within it that is opened in turn.  What is held at any time is the row being walked of each
+// open composite and the composites within it that may yet fit on a line, whose text is no wider than a
+// line.  The output is the same as that of laying out the whole document.
+
+// stream lays out the description of a value as the value is walked.
+type stream struct {
+	l  layouter
+	tw *tabwriter.Writer // the tabwriter that aligns columns, or nil if they are not aligned
+}
+
+func newStream(f io.Writer, width int, align bool) *stream {
+	s := &stream{l: layouter{f: f, width: width, align: align}}
+	if align {
+		s.tw = newTabwriter(f)
+		s.l.f = s.tw
+	}
+	return s
+}
+
+// render writes a document at a level, followed up to the next newline by the documents in follow.
+func (s *stream) render(level int, flat bool, d *doc, follow ...*doc) {
+	s.l.follow = s.l.follow[:0]
+	for i := len(follow) - 1; i >= 0; i-- {
+		s.l.follow = append(s.l.follow, layoutItem{indent: level, d: follow[i]})
+	}
+	s.l.render(layoutItem{indent: level, flat: flat, d: d})
+}
+
+// close writes what the tabwriter holds, and returns the first error from the Writer.
+func (s *stream) close() error {
+	if s.l.err == nil && s.tw != nil {
+		s.l.err = s.tw.Flush()
+	}
+	return s.l.err
+}
+
+// failed returns the first error from the Writer of a stream, which may be nil.
+func (s *stream) failed() error {
+	if s == nil {
+		return nil
+	}
+	return s.l.err
+}
+
+// docWidth returns the width of a document written flat.
+func docWidth(d *doc) int {
+	if d.kind == docText {
+		return utf8.RuneCountInString(d.text)
+	}
+	return flatWidth(d)
+}
+
+// sizing reports whether the widths of composites are counted, to find those wider than a line.
+func (p *goPrinter) sizing() bool {
+	return p.stream != nil && p.stream.l.width > 0
+}
+
+// grow adds to the widths of the composites that are not open.
+func (p *goPrinter) grow(n int) {
+	for j := len(p.frames) - 1; j >= p.opened; j-- {
+		p.frames[j].size += n
+	}
+}
+
+// openFrames opens the composites whose groups are known to be broken, or known to be flat when no group
+// is broken.  A composite is opened once it has a row, if the composite that holds it is open and it is
+// not in a key.
+func (p *goPrinter) openFrames() {
+	for p.opened < len(p.frames) {
+		fr := &p.frames[p.opened]
+		if fr.n == 0 || p.opened > 0 && p.frames[p.opened-1].key != nil {
+			return
+		}
+		if w := p.stream.l.width; w > 0 && fr.size <= w {
+			return
+		}
+		p.open(p.opened)
+		p.opened++
+	}
+}
+
+// open writes the start of the row of the composite holding the jth composite, up to the composite, then
+// the composite's head and all but its last row.
+func (p *goPrinter) open(j int) {
+	s := p.stream
+	fr := &p.frames[j]
+	fr.open, fr.flat = true, s.l.width < 0
+	level := 0
+	if j > 0 {
+		level = p.frames[j-1].indent
+		p.startRow(&p.frames[j-1], fr.head)
+	}
+	if fr.flat {
+		s.render(level, true, cat(fr.head...))
+	} else {
+		s.render(level, false, cat(fr.head...), brk(""))
+	}
+	last := len(fr.rows) - 1
+	for _, r := range fr.rows[:last] {
+		p.writeRow(fr, r)
+	}
+	fr.rows = append(fr.rows[:0], fr.rows[last])
+}
+
+// startRow writes the start of the last row of an open composite, which holds a composite that is being
+// opened with the given head.
+func (p *goPrinter) startRow(fr *goFrame, head []*doc) {
+	s := p.stream
+	r := fr.rows[len(fr.rows)-1]
+	key, value := r.docs[0], r.docs[1]
+	if fr.flat {
+		if fr.rs.i > 0 {
+			s.l.write(", ")
+		}
+		if key != nil {
+			s.render(fr.indent, true, key)
+			s.l.write(": ")
+		}
+		s.render(fr.indent, true, value)
+	} else {
+		s.l.startRow(&fr.rs, fr.rowsDoc(), r, false)
+		if key != nil {
+			s.render(fr.indent, false, key, text(","))
+			s.l.write(": ")
+		}
+		s.render(fr.indent, false, value, append(head, brk(""))...)
+	}
+	value.docs = nil
+	fr.started = true
+}
+
+// writeRow writes a complete row of an open composite.
+func (p *goPrinter) writeRow(fr *goFrame, r *doc) {
+	s := p.stream
+	if fr.flat {
+		if fr.rs.i > 0 {
+			s.l.write(", ")
+		}
+		s.render(fr.indent, true, r)
+		fr.rs.i++
+		return
+	}
+	s.l.follow = append(s.l.follow[:0], layoutItem{d: text(",")})
+	s.l.row(&fr.rs, fr.rowsDoc(), r, fr.n)
+}
+
+// finishRow writes the last row of an open composite, or the rest of it if it has been started.
+func (p *goPrinter) finishRow(fr *goFrame) {
+	r := fr.rows[len(fr.rows)-1]
+	if !fr.started {
+		p.writeRow(fr, r)
+		return
+	}
+	s := p.stream
+	if fr.flat {
+		s.render(fr.indent, true, r.docs[1])
+		fr.rs.i++
+	} else {
+		s.render(fr.indent, false, r.docs[1], text(","))
+		s.l.endRow(&fr.rs)
+	}
+	fr.started = false
+}
+
+// closeFrame writes the last row of an open composite and its closing brace.
+func (p *goPrinter) closeFrame(fr *goFrame) {
+	s := p.stream
+	p.finishRow(fr)
+	if !fr.flat {
+		s.l.write(",")
+		s.l.newline(fr.indent-1, false)
+	}
+	s.l.write("}")
+}
+
+// rowsDoc returns the rows of a composite, without their contents, for writing them one at a time.
+func (fr *goFrame) rowsDoc() *doc {
+	return elemRows(fr.indent)
+}
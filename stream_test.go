@@ -0,0 +1,102 @@
+package describe
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// wholeDocument describes a value by building its whole document and then laying it out.
+func wholeDocument(d *Describer, v interface{}) string {
+	p := &goPrinter{}
+	w := walker{p: p, d: d, seen: refs{}}
+	w.walk(reflect.TypeOf(v), reflect.ValueOf(v), "")
+	var buf bytes.Buffer
+	layout(&buf, cat(p.docs...), d.width(), d.Align)
+	return buf.String()
+}
+
+func TestDescriber_Value_stream(t *testing.T) {
+	long := strings.Repeat("x", 50)
+	values := []struct {
+		name string
+		v    interface{}
+	}{
+		{"scalar", 42},
+		{"pointer", &Obj{Field: 1}},
+		{"nested", []Payload{{ID: "a", Items: []Item{{Name: long}, {Name: "b", Kind: 2}}}, {ID: "c"}}},
+		{"pointers", &Item{Name: "a", Parent: &Item{Name: long, Parent: &Item{Name: "c"}}}},
+		{"struct keys", map[Obj][]string{{1}: {long, long}, {2}: nil, {3}: {"a"}}},
+		{"map values", map[string]map[string]int{"a": {"b": 1}, long: {long: 2, "c": 3}}},
+		{"keys of different lengths", map[string]int{"a": 1, "bb": 2, long: 3, long + long: 4, "c": 5}},
+		{"empty", []interface{}{[]int{}, map[int]int{}, struct{}{}, nil}},
+		{"cycle", cyclicNode()},
+		{"interfaces", []interface{}{1, "a", []int{1, 2}, &Obj{}, [2]Obj{}}},
+	}
+	describers := []struct {
+		name string
+		d    *Describer
+	}{
+		{"default", &Describer{}},
+		{"width 20", &Describer{Width: 20}},
+		{"width 80", &Describer{Width: 80}},
+		{"compact", &Describer{Compact: true}},
+		{"align", &Describer{Align: true}},
+		{"align width 40", &Describer{Align: true, Width: 40}},
+		{"omit zero", &Describer{OmitZero: true, Width: 30}},
+		{"elements", &Describer{Limits: Limits{Elements: 1}, Width: 30}},
+	}
+	for _, dt := range describers {
+		for _, vt := range values {
+			t.Run(dt.name+"/"+vt.name, func(t *testing.T) {
+				if got, want := dt.d.Value(vt.v), wholeDocument(dt.d, vt.v); got != want {
+					t.Errorf("Describer.Value() = %q, want %q", got, want)
+				}
+			})
+		}
+	}
+}
+
+// Streamed is an integer whose Formatter notes how much of a description has been written.
+type Streamed int
+
+func TestFprint_stream(t *testing.T) {
+	var buf bytes.Buffer
+	var written []int
+	d := &Describer{}
+	d.RegisterFormatter(Streamed(0), func(s *State, v reflect.Value) {
+		written = append(written, buf.Len())
+		fmt.Fprintf(s, "%d", v.Int())
+	})
+	v := map[string][]Streamed{"a": make([]Streamed, 1000), "b": make([]Streamed, 1000)}
+	if err := d.Fprint(&buf, v); err != nil {
+		t.Fatalf("Describer.Fprint() = %v", err)
+	}
+	// Each element is written before the next is walked.
+	for i := 1; i < len(written); i++ {
+		if written[i] <= written[i-1] {
+			t.Fatalf("Describer.Fprint() had written %d bytes before element %d, as before element %d", written[i], i, i-1)
+		}
+	}
+	if got, want := buf.String(), wholeDocument(d, v); got != want {
+		t.Errorf("Describer.Fprint() wrote %q, want %q", got, want)
+	}
+}
+
+func TestFprint_stream_error(t *testing.T) {
+	walked := 0
+	d := &Describer{}
+	d.RegisterFormatter(Streamed(0), func(s *State, v reflect.Value) {
+		walked++
+		fmt.Fprintf(s, "%d", v.Int())
+	})
+	w := &failWriter{n: 100}
+	if err := d.Fprint(w, make([]Streamed, 1e6)); err == nil {
+		t.Fatalf("Describer.Fprint() to a full writer succeeded")
+	}
+	if walked > 1e4 {
+		t.Errorf("Describer.Fprint() walked %d elements after the writer failed", walked)
+	}
+}
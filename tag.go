@@ -0,0 +1,28 @@
+package describe
+
+import (
+	"reflect"
+)
+
+// Struct fields may have a describe tag holding a comma separated list of options that control how their
+// values are described:
+//
+//	-           the field is left out
+//	omitempty   the field is left out if its value is the zero value
+//	redact      the value is redacted, as by the rules of a Redaction
+//	noncompare  the value is described but Compare does not compare it
+//	hex, octal, binary, decimal, char
+//	            integers are written in the format of the same name
+//	string      bytes are written as a string literal if they are text
+//
+// The tag `describe:"-"` leaves out the field, so "-" cannot otherwise be an option.
+
+// skipField reports whether a struct field with a describe tag is left out of descriptions.
+func skipField(tag string, v reflect.Value) bool {
+	return tag == "-" || tagHas(tag, "omitempty") && v.IsZero()
+}
+
+// anyEqual is the equality function for the values of fields tagged noncompare.
+func anyEqual(a, b reflect.Value) bool {
+	return true
+}
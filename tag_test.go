@@ -0,0 +1,66 @@
+package describe
+
+import (
+	"testing"
+)
+
+type Login struct {
+	User     string
+	Password string            `describe:"redact"`
+	Token    *string           `describe:"redact"`
+	Cache    map[string]string `describe:"-"`
+	Note     string            `describe:"omitempty"`
+	Seen     int               `describe:"noncompare"`
+}
+
+type Hidden struct {
+	Cache []int `describe:"-"`
+}
+
+func TestValue_tags(t *testing.T) {
+	token := "secret"
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{
+			name: "tags",
+			v:    Login{User: "bob", Password: "hunter2", Token: &token, Cache: map[string]string{"a": "b"}, Seen: 3},
+			want: "Login{\n\tUser: \"bob\",\n\tPassword: \"\" /* redacted, 7 bytes */,\n\tToken: nil /* redacted */,\n\tSeen: 3,\n}",
+		},
+		{
+			name: "omitempty with a value",
+			v:    Login{Note: "n"},
+			want: "Login{\n\tUser: \"\",\n\tPassword: \"\" /* redacted, 0 bytes */,\n\tToken: nil,\n\tNote: \"n\",\n\tSeen: 0,\n}",
+		},
+		{
+			name: "every field left out",
+			v:    Hidden{Cache: []int{1}},
+			want: "Hidden{}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Value(tt.v); got != tt.want {
+				t.Errorf("Value() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare_noncompare(t *testing.T) {
+	DiffFunc(nil)
+	if !Compare(Login{User: "bob", Seen: 1}, Login{User: "bob", Seen: 2}) {
+		t.Errorf("Compare() = false for values that differ in a noncompare field")
+	}
+	if Compare(Login{User: "bob"}, Login{User: "alice"}) {
+		t.Errorf("Compare() = true for values that differ")
+	}
+}
+
+func TestYAML_tags(t *testing.T) {
+	if got, want := YAML(map[string]Hidden{"h": {Cache: []int{1}}}), "h: {} # Hidden\n"; got != want {
+		t.Errorf("YAML() = %q, want %q", got, want)
+	}
+}
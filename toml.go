@@ -0,0 +1,179 @@
+package describe
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TOML returns a flat, INI-like listing of a value with one "path = value" line for each scalar it holds,
+// such as "Server.Port = 8080".  Fields are selected with dots and elements and map entries with brackets.
+// Empty composites are written as [] or {}, and named types are noted in comments.  Map keys are ordered
+// and cycles are reported in the same way as Value.  Only the values of fields with the describe tag option
+// redact are redacted; Describer.TOML applies the rules of a Describer.
+func TOML(v interface{}) string {
+	return (&Describer{}).TOML(v)
+}
+
+// TOML returns a listing of a value as the TOML function does, with the Describer's Formatters, redaction
+// rules and limits.
+func (d *Describer) TOML(v interface{}) string {
+	var buf bytes.Buffer
+	d.Print(v, &tomlPrinter{f: &buf})
+	return buf.String()
+}
+
+// tomlFrame is a composite that is being listed.
+type tomlFrame struct {
+	path    string
+	list    bool
+	n       int    // the number of entries listed so far
+	empty   string // how the composite is listed if it has no entries
+	comment string
+}
+
+// tomlPrinter is the Printer used by TOML.
+type tomlPrinter struct {
+	f      io.Writer
+	path   string // the path of the next value
+	frames []tomlFrame
+	key    string // the path of the value of the map entry being listed
+}
+
+func (p *tomlPrinter) begin(t reflect.Type, n int, empty string) bool {
+	if n == 0 {
+		tomlLine(p.f, p.path, empty, namedType(t))
+		return false
+	}
+	p.frames = append(p.frames, tomlFrame{path: p.path, list: empty == "[]", empty: empty, comment: namedType(t)})
+	return true
+}
+
+func (p *tomlPrinter) top() string {
+	return p.frames[len(p.frames)-1].path
+}
+
+func (p *tomlPrinter) end() {
+	fr := p.frames[len(p.frames)-1]
+	p.frames = p.frames[:len(p.frames)-1]
+	if fr.n == 0 {
+		// Every entry was left out.
+		tomlLine(p.f, fr.path, fr.empty, fr.comment)
+	}
+}
+
+func (p *tomlPrinter) Scalar(t reflect.Type, v reflect.Value) {
+	comment := namedType(t)
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		tomlLine(p.f, p.path, strconv.Quote(goScalar(t, v)), "")
+	case reflect.Float32, reflect.Float64:
+		tomlLine(p.f, p.path, strconv.FormatFloat(v.Float(), 'g', -1, t.Bits()), comment)
+	case reflect.Complex64, reflect.Complex128:
+		tomlLine(p.f, p.path, strconv.Quote(strconv.FormatComplex(v.Complex(), 'g', -1, t.Bits())), comment)
+	case reflect.String:
+		tomlLine(p.f, p.path, strconv.Quote(v.String()), comment)
+	default:
+		tomlLine(p.f, p.path, yamlScalar(t, v), comment)
+	}
+}
+
+func (p *tomlPrinter) Nil(t reflect.Type) {
+	tomlLine(p.f, p.path, "nil", "")
+}
+
+func (p *tomlPrinter) Ref(t reflect.Type, path string) {
+	tomlLine(p.f, p.path, "nil", "cycle: "+pathString(path))
+}
+
+func (p *tomlPrinter) Pointer(t reflect.Type) {}
+
+func (p *tomlPrinter) BeginStruct(t reflect.Type, v reflect.Value) bool {
+	n := 0
+	for i := 0; i < t.NumField(); i++ {
+		if sf := t.Field(i); sf.PkgPath == "" && !skipField(sf.Tag.Get("describe"), v.Field(i)) {
+			n++
+		}
+	}
+	return p.begin(t, n, "{}")
+}
+
+func (p *tomlPrinter) Field(sf reflect.StructField) bool {
+	if sf.PkgPath != "" {
+		return false
+	}
+	p.path = fieldPath(p.top(), sf.Name)
+	p.frames[len(p.frames)-1].n++
+	return true
+}
+
+func (p *tomlPrinter) EndStruct(t reflect.Type) {
+	p.end()
+}
+
+func (p *tomlPrinter) BeginList(t reflect.Type, v reflect.Value) bool {
+	if t.Elem().Kind() == reflect.Uint8 && v.Len() > 0 {
+		tomlLine(p.f, p.path, strconv.Quote(string(byteSlice(v))), namedType(t))
+		return false
+	}
+	return p.begin(t, v.Len(), "[]")
+}
+
+func (p *tomlPrinter) Elem(i int) {
+	p.frames[len(p.frames)-1].n++
+	if p.key != "" {
+		p.path, p.key = p.key, ""
+		return
+	}
+	p.path = indexPath(p.top(), i)
+}
+
+func (p *tomlPrinter) EndList(t reflect.Type) {
+	p.end()
+}
+
+func (p *tomlPrinter) BeginMap(t reflect.Type, v reflect.Value) bool {
+	return p.begin(t, v.Len(), "{}")
+}
+
+func (p *tomlPrinter) Key(i int, k reflect.Value) bool {
+	p.key = keyPath(p.top(), k)
+	return false
+}
+
+func (p *tomlPrinter) EndMap(t reflect.Type) {
+	p.end()
+}
+
+func (p *tomlPrinter) More(n int) {
+	fr := p.frames[len(p.frames)-1]
+	what := "more entry"
+	if fr.list {
+		what = "more element"
+	}
+	if key := strings.TrimPrefix(fr.path, "."); key != "" {
+		fmt.Fprintf(p.f, "# %s: %s\n", key, count(n, what))
+		return
+	}
+	fmt.Fprintf(p.f, "# %s\n", count(n, what))
+}
+
+func (p *tomlPrinter) Text(t reflect.Type, s string) {
+	tomlLine(p.f, p.path, strconv.Quote(s), namedType(t))
+}
+
+// tomlLine writes one line of the listing.  The described value itself has no key.
+func tomlLine(f io.Writer, path, value, comment string) {
+	key := strings.TrimPrefix(path, ".")
+	if key != "" {
+		fmt.Fprintf(f, "%s = ", key)
+	}
+	fmt.Fprintf(f, "%s", value)
+	if comment != "" {
+		fmt.Fprintf(f, " # %s", comment)
+	}
+	fmt.Fprintf(f, "\n")
+}
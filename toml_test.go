@@ -0,0 +1,75 @@
+package describe
+
+import (
+	"testing"
+)
+
+func TestTOML(t *testing.T) {
+	type args struct {
+		v interface{}
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "int",
+			args: args{
+				v: 1,
+			},
+			want: "1\n",
+		},
+		{
+			name: "slice of int",
+			args: args{
+				v: []int{1, 2},
+			},
+			want: "[0] = 1\n[1] = 2\n",
+		},
+		{
+			name: "struct",
+			args: args{
+				v: Server{
+					Host:   "localhost",
+					Port:   8080,
+					Tags:   []string{"x"},
+					Labels: map[string]string{"env": "prod"},
+					Mode:   2,
+				},
+			},
+			want: `Host = "localhost"
+Port = 8080
+Tags[0] = "x"
+Labels["env"] = "prod"
+Mode = 2 # Foo
+`,
+		},
+		{
+			name: "empty composites",
+			args: args{
+				v: struct {
+					A []int
+					B map[int]int
+					C *int
+					D struct{}
+				}{},
+			},
+			want: "A = []\nB = {}\nC = nil\nD = {}\n",
+		},
+		{
+			name: "cycle",
+			args: args{
+				v: cyclicNode(),
+			},
+			want: "Name = \"a\"\nNext = nil # cycle: .\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TOML(tt.args.v); got != tt.want {
+				t.Errorf("TOML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package describe
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Visitor is called by Walk for each part of a value, with the path to that part written as a Go selector
+// and index expression relative to the value, such as ".Servers[2].Port".  The path of the value itself is
+// empty.
+//
+// If a Visitor returns SkipValue, Walk does not walk the parts of that value.  If it returns SkipAll, or
+// any other error, Walk stops and returns the error, or nil for SkipAll.
+type Visitor func(path string, t reflect.Type, v reflect.Value) error
+
+// SkipValue is returned by a Visitor to skip the parts of the value it was called with.
+var SkipValue = errors.New("skip this value")
+
+// SkipAll is returned by a Visitor to stop the walk.
+var SkipAll = errors.New("skip everything")
+
+// Walk calls a Visitor for a value and each of its parts in the order in which Value describes them.
+// Pointers and interfaces are visited as well as the values they refer to, with the same path.  Struct
+// fields are visited whether or not they are exported, so a Visitor should read values with the kind
+// specific accessors such as Int and String rather than Interface.  Map entries are visited in the order
+// of their keys, but the keys themselves are not visited, and a pointer, map or slice that is reached
+// again while it is being walked is not visited a second time.  Keys such as pointers that have no order
+// are ordered by their descriptions, and keys that are described alike, or are NaNs, by the descriptions
+// of their values, so entries that are described alike are visited in no particular order.
+func Walk(v interface{}, visit Visitor) error {
+	w := walker{p: nopPrinter{}, visit: visit, seen: refs{}}
+	w.walk(reflect.TypeOf(v), reflect.ValueOf(v), "")
+	if w.err == SkipAll {
+		return nil
+	}
+	return w.err
+}
+
+// nopPrinter is the Printer used by Walk.  It prints nothing and walks everything except map keys.
+type nopPrinter struct{}
+
+func (nopPrinter) Scalar(t reflect.Type, v reflect.Value)           {}
+func (nopPrinter) Nil(t reflect.Type)                               {}
+func (nopPrinter) Ref(t reflect.Type, path string)                  {}
+func (nopPrinter) Pointer(t reflect.Type)                           {}
+func (nopPrinter) BeginStruct(t reflect.Type, v reflect.Value) bool { return true }
+func (nopPrinter) Field(sf reflect.StructField) bool                { return true }
+func (nopPrinter) EndStruct(t reflect.Type)                         {}
+func (nopPrinter) BeginList(t reflect.Type, v reflect.Value) bool   { return true }
+func (nopPrinter) Elem(i int)                                       {}
+func (nopPrinter) EndList(t reflect.Type)                           {}
+func (nopPrinter) BeginMap(t reflect.Type, v reflect.Value) bool    { return true }
+func (nopPrinter) Key(i int, k reflect.Value) bool                  { return false }
+func (nopPrinter) EndMap(t reflect.Type)                            {}
+func (nopPrinter) Text(t reflect.Type, s string)                    {}
+func (nopPrinter) More(n int)                                       {}
@@ -0,0 +1,152 @@
+package describe
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Pair struct {
+	Key   string
+	Value interface{}
+}
+
+func TestWalk(t *testing.T) {
+	errStop := errors.New("stop")
+	type args struct {
+		v     interface{}
+		visit func(path string, t reflect.Type, v reflect.Value) error
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr error
+	}{
+		{
+			name: "scalar",
+			args: args{
+				v: 1,
+			},
+			want: ":int",
+		},
+		{
+			name: "nil",
+			args: args{
+				v: nil,
+			},
+			want: ":nil",
+		},
+		{
+			name: "struct",
+			args: args{
+				v: Pair{Key: "a", Value: []int{1}},
+			},
+			want: ":Pair .Key:string .Value:interface .Value:slice .Value[0]:int",
+		},
+		{
+			name: "map",
+			args: args{
+				v: map[string]*int{"b": nil, "a": new(int)},
+			},
+			want: `:map ["a"]:ptr ["a"]:int ["b"]:ptr`,
+		},
+		{
+			name: "uintptr keys",
+			args: args{
+				v: map[uintptr]bool{9: true, 3: true, 40: true, 1: true, 12: true, 7: true, 25: true, 5: true},
+			},
+			want: `:map [1]:bool [3]:bool [5]:bool [7]:bool [9]:bool [12]:bool [25]:bool [40]:bool`,
+		},
+		{
+			name: "skip value",
+			args: args{
+				v: []Obj{{Field: 1}, {Field: 2}},
+				visit: func(path string, t reflect.Type, v reflect.Value) error {
+					if path == "[0]" {
+						return SkipValue
+					}
+					return nil
+				},
+			},
+			want: ":slice [0]:Obj [1]:Obj [1].Field:int",
+		},
+		{
+			name: "skip all",
+			args: args{
+				v: []int{1, 2, 3},
+				visit: func(path string, t reflect.Type, v reflect.Value) error {
+					if path == "[1]" {
+						return SkipAll
+					}
+					return nil
+				},
+			},
+			want: ":slice [0]:int [1]:int",
+		},
+		{
+			name: "error",
+			args: args{
+				v: []int{1, 2, 3},
+				visit: func(path string, t reflect.Type, v reflect.Value) error {
+					if path == "[0]" {
+						return errStop
+					}
+					return nil
+				},
+			},
+			want:    ":slice [0]:int",
+			wantErr: errStop,
+		},
+		{
+			name: "cycle",
+			args: args{
+				v: cyclicNode(),
+			},
+			want: ":ptr :Node .Name:string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := Walk(tt.args.v, func(path string, typ reflect.Type, v reflect.Value) error {
+				name := "nil"
+				if typ != nil {
+					name = typ.Name()
+					if name == "" {
+						name = typ.Kind().String()
+					}
+				}
+				got = append(got, path+":"+name)
+				if tt.args.visit != nil {
+					return tt.args.visit(path, typ, v)
+				}
+				return nil
+			})
+			if err != tt.wantErr {
+				t.Errorf("Walk() error = %v, want %v", err, tt.wantErr)
+			}
+			if s := strings.Join(got, " "); s != tt.want {
+				t.Errorf("Walk() = %v, want %v", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalk_equalKeys(t *testing.T) {
+	a, b := 1, 1
+	v := map[*int]string{&a: "y", &b: "x"}
+	for i := 0; i < 100; i++ {
+		var got []string
+		Walk(v, func(path string, t reflect.Type, v reflect.Value) error {
+			if t.Kind() == reflect.String {
+				got = append(got, v.String())
+			}
+			return nil
+		})
+		if strings.Join(got, " ") != "x y" {
+			t.Fatalf("Walk() visited %v, want [x y]", got)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package describe
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// YAML returns a YAML document describing a value.  Structs and maps become mappings, arrays and slices
+// become sequences and pointers and interfaces are followed to the values they refer to.  Named types are
+// noted in comments, and scalars that a YAML reader would take for another type are quoted or tagged.
+// Map keys are ordered and cycles are reported in the same way as Value.  Only the values of fields with
+// the describe tag option redact are redacted; Describer.YAML applies the rules of a Describer.
+func YAML(v interface{}) string {
+	return (&Describer{}).YAML(v)
+}
+
+// YAML returns a YAML document describing a value as the YAML function does, with the Describer's
+// Formatters, redaction rules and limits.
+func (d *Describer) YAML(v interface{}) string {
+	var buf bytes.Buffer
+	d.Print(v, &yamlPrinter{f: &buf})
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+// yamlContext is the position at which a YAML node is written.
+type yamlContext int
+
+const (
+	yamlRoot yamlContext = iota // at the start of the document
+	yamlKey                     // after a mapping key and its colon
+	yamlItem                    // after a sequence dash
+)
+
+// yamlPrinter is the Printer used by YAML.
+type yamlPrinter struct {
+	f      io.Writer
+	ctx    yamlContext // the position of the next node
+	level  int         // the level at which the entries of the next node are written
+	frames []yamlFrame
+}
+
+// yamlFrame is a mapping or sequence that is being written.
+type yamlFrame struct {
+	ctx     yamlContext
+	level   int
+	inline  bool // whether the first entry follows on the line of the header
+	n       int  // the number of entries written so far
+	list    bool
+	comment string // the comment noting the type of the mapping or sequence
+	empty   string // how the mapping or sequence is written if it has no entries
+}
+
+func (p *yamlPrinter) sep() string {
+	if p.ctx == yamlRoot {
+		return ""
+	}
+	return " "
+}
+
+// comment returns the comment noting the type of the next node.  Sequence items are not commented as
+// their type is that of the sequence.
+func (p *yamlPrinter) comment(t reflect.Type) string {
+	if tn := namedType(t); tn != "" && p.ctx != yamlItem {
+		return " # " + tn
+	}
+	return ""
+}
+
+// begin starts a mapping or sequence, or writes the whole of it if it is empty.  Its header is written
+// with its first entry, as entries may yet be left out.
+func (p *yamlPrinter) begin(t reflect.Type, n int, empty string, list bool) bool {
+	comment := p.comment(t)
+	if n == 0 {
+		fmt.Fprintf(p.f, "%s%s%s", p.sep(), empty, comment)
+		return false
+	}
+	p.frames = append(p.frames, yamlFrame{ctx: p.ctx, level: p.level, list: list, comment: comment, empty: empty})
+	return true
+}
+
+// header writes whatever precedes the first entry of a mapping or sequence.
+func (p *yamlPrinter) header(fr *yamlFrame) {
+	fr.inline = true
+	switch {
+	case fr.ctx == yamlRoot && fr.comment != "":
+		fmt.Fprintf(p.f, "#%s\n", fr.comment[2:])
+	case fr.ctx != yamlRoot:
+		fmt.Fprintf(p.f, "%s", fr.comment)
+		fr.inline = fr.ctx == yamlItem && fr.comment == ""
+	}
+}
+
+// entry starts the next entry of the mapping or sequence being written.
+func (p *yamlPrinter) entry() {
+	fr := &p.frames[len(p.frames)-1]
+	if fr.n == 0 {
+		p.header(fr)
+	}
+	switch {
+	case fr.n == 0 && fr.inline && fr.ctx == yamlRoot:
+	case fr.n == 0 && fr.inline:
+		fmt.Fprintf(p.f, " ")
+	default:
+		fmt.Fprintf(p.f, "\n%s", strings.Repeat("  ", fr.level))
+	}
+	fr.n++
+	p.level = fr.level + 1
+}
+
+func (p *yamlPrinter) end() {
+	fr := p.frames[len(p.frames)-1]
+	p.frames = p.frames[:len(p.frames)-1]
+	if fr.n == 0 {
+		// Every entry was left out.
+		p.ctx = fr.ctx
+		fmt.Fprintf(p.f, "%s%s%s", p.sep(), fr.empty, fr.comment)
+	}
+}
+
+func (p *yamlPrinter) Scalar(t reflect.Type, v reflect.Value) {
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		fmt.Fprintf(p.f, "%s%s", p.sep(), strconv.Quote(goScalar(t, v)))
+	default:
+		fmt.Fprintf(p.f, "%s%s%s", p.sep(), yamlScalar(t, v), p.comment(t))
+	}
+}
+
+func (p *yamlPrinter) Nil(t reflect.Type) {
+	fmt.Fprintf(p.f, "%snull", p.sep())
+}
+
+func (p *yamlPrinter) Ref(t reflect.Type, path string) {
+	fmt.Fprintf(p.f, "%snull # cycle: %s", p.sep(), pathString(path))
+}
+
+func (p *yamlPrinter) Pointer(t reflect.Type) {}
+
+func (p *yamlPrinter) BeginStruct(t reflect.Type, v reflect.Value) bool {
+	n := 0
+	for i := 0; i < t.NumField(); i++ {
+		if sf := t.Field(i); sf.PkgPath == "" && !skipField(sf.Tag.Get("describe"), v.Field(i)) {
+			n++
+		}
+	}
+	return p.begin(t, n, "{}", false)
+}
+
+func (p *yamlPrinter) Field(sf reflect.StructField) bool {
+	if sf.PkgPath != "" {
+		// Unexported fields are left out, as Value leaves out their contents.
+		return false
+	}
+	p.entry()
+	fmt.Fprintf(p.f, "%s:", sf.Name)
+	p.ctx = yamlKey
+	return true
+}
+
+func (p *yamlPrinter) EndStruct(t reflect.Type) {
+	p.end()
+}
+
+func (p *yamlPrinter) BeginList(t reflect.Type, v reflect.Value) bool {
+	if t.Elem().Kind() == reflect.Uint8 && v.Len() > 0 {
+		fmt.Fprintf(p.f, "%s!!binary %s%s", p.sep(), base64.StdEncoding.EncodeToString(byteSlice(v)), p.comment(t))
+		return false
+	}
+	return p.begin(t, v.Len(), "[]", true)
+}
+
+func (p *yamlPrinter) Elem(i int) {
+	fr := &p.frames[len(p.frames)-1]
+	if fr.list {
+		p.entry()
+		fmt.Fprintf(p.f, "-")
+		p.ctx = yamlItem
+		return
+	}
+	p.ctx = yamlKey
+}
+
+func (p *yamlPrinter) EndList(t reflect.Type) {
+	p.end()
+}
+
+func (p *yamlPrinter) BeginMap(t reflect.Type, v reflect.Value) bool {
+	return p.begin(t, v.Len(), "{}", false)
+}
+
+func (p *yamlPrinter) Key(i int, k reflect.Value) bool {
+	p.entry()
+	fmt.Fprintf(p.f, "%s:", yamlKeyString(k.Type(), k))
+	return false
+}
+
+func (p *yamlPrinter) EndMap(t reflect.Type) {
+	p.end()
+}
+
+func (p *yamlPrinter) More(n int) {
+	what := "more entry"
+	if p.frames[len(p.frames)-1].list {
+		what = "more element"
+	}
+	p.entry()
+	fmt.Fprintf(p.f, "# %s", count(n, what))
+}
+
+func (p *yamlPrinter) Text(t reflect.Type, s string) {
+	fmt.Fprintf(p.f, "%s%s%s", p.sep(), strconv.Quote(s), p.comment(t))
+}
+
+func yamlKeyString(t reflect.Type, k reflect.Value) string {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32,
+		reflect.Float64, reflect.String:
+		return yamlScalar(t, k)
+	case reflect.Interface:
+		if !k.IsNil() {
+			return yamlKeyString(k.Elem().Type(), k.Elem())
+		}
+		return "null"
+	}
+	// Keys that are not scalars are written as Go expressions in a string.
+	var buf bytes.Buffer
+	describeValue(&buf, t, k, 0)
+	return strconv.Quote(buf.String())
+}
+
+// yamlScalar returns the YAML form of a value of a basic kind.
+func yamlScalar(t reflect.Type, v reflect.Value) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		x := v.Float()
+		switch {
+		case math.IsNaN(x):
+			return ".nan"
+		case math.IsInf(x, 1):
+			return ".inf"
+		case math.IsInf(x, -1):
+			return "-.inf"
+		}
+		s := strconv.FormatFloat(x, 'g', -1, t.Bits())
+		if !strings.ContainsAny(s, ".e") {
+			// Keep the value a float rather than an int when read back.
+			s += ".0"
+		}
+		return s
+	case reflect.Complex64, reflect.Complex128:
+		return "!!str " + strconv.Quote(strconv.FormatComplex(v.Complex(), 'g', -1, t.Bits()))
+	case reflect.String:
+		s := v.String()
+		if yamlPlain(s) {
+			return s
+		}
+		return strconv.Quote(s)
+	}
+	return ""
+}
+
+// yamlPlain reports whether s can be written as a plain scalar and still be read back as the same string.
+func yamlPlain(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return false
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~", ".inf", "-.inf", "+.inf", ".nan":
+		return false
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return false
+	}
+	if _, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return false
+	}
+	if strings.ContainsRune("-?:,[]{}#&*!|>'\"%@`", rune(s[0])) {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// namedType returns the name of a type declared in a package, or "" for predeclared and unnamed types.
+func namedType(t reflect.Type) string {
+	if t.PkgPath() == "" {
+		return ""
+	}
+	return typeName(t)
+}
+
+// byteSlice returns the contents of an array or slice of bytes.
+func byteSlice(v reflect.Value) []byte {
+	if v.Kind() == reflect.Slice && v.Type().Elem() == reflect.TypeOf(byte(0)) {
+		return v.Bytes()
+	}
+	b := make([]byte, v.Len())
+	for i := range b {
+		b[i] = byte(v.Index(i).Uint())
+	}
+	return b
+}
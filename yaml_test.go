@@ -0,0 +1,175 @@
+package describe
+
+import (
+	"testing"
+)
+
+type Server struct {
+	Host   string
+	Port   uint16
+	Tags   []string
+	Labels map[string]string
+	Mode   Foo
+	port   int
+}
+
+type Node struct {
+	Name string
+	Next *Node
+}
+
+func cyclicNode() *Node {
+	n := &Node{Name: "a"}
+	n.Next = n
+	return n
+}
+
+func TestYAML(t *testing.T) {
+	type args struct {
+		v interface{}
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "nil",
+			args: args{
+				v: nil,
+			},
+			want: "null\n",
+		},
+		{
+			name: "int",
+			args: args{
+				v: 1,
+			},
+			want: "1\n",
+		},
+		{
+			name: "float",
+			args: args{
+				v: 2.0,
+			},
+			want: "2.0\n",
+		},
+		{
+			name: "plain string",
+			args: args{
+				v: "abc",
+			},
+			want: "abc\n",
+		},
+		{
+			name: "ambiguous string",
+			args: args{
+				v: "true",
+			},
+			want: "\"true\"\n",
+		},
+		{
+			name: "bytes",
+			args: args{
+				v: []byte("hi"),
+			},
+			want: "!!binary aGk=\n",
+		},
+		{
+			name: "slice of int",
+			args: args{
+				v: []int{1, 2},
+			},
+			want: "- 1\n- 2\n",
+		},
+		{
+			name: "nested slices",
+			args: args{
+				v: [][]int{{1, 2}, {3}},
+			},
+			want: "- - 1\n  - 2\n- - 3\n",
+		},
+		{
+			name: "map",
+			args: args{
+				v: map[string]int{"b": 2, "a": 1},
+			},
+			want: "a: 1\nb: 2\n",
+		},
+		{
+			name: "struct",
+			args: args{
+				v: Server{
+					Host:   "localhost",
+					Port:   8080,
+					Tags:   []string{"x", "z"},
+					Labels: map[string]string{},
+					Mode:   2,
+				},
+			},
+			want: `# Server
+Host: localhost
+Port: 8080
+Tags:
+  - x
+  - z
+Labels: {}
+Mode: 2 # Foo
+`,
+		},
+		{
+			name: "slice of structs",
+			args: args{
+				v: []Obj{{Field: 1}, {Field: 2}},
+			},
+			want: "- Field: 1\n- Field: 2\n",
+		},
+		{
+			name: "interface elements",
+			args: args{
+				v: []interface{}{1, "a", nil},
+			},
+			want: "- 1\n- a\n- null\n",
+		},
+		{
+			name: "cycle",
+			args: args{
+				v: cyclicNode(),
+			},
+			want: "# Node\nName: a\nNext: null # cycle: .\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := YAML(tt.args.v); got != tt.want {
+				t.Errorf("YAML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_yamlPlain(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{s: "abc", want: true},
+		{s: "a b", want: true},
+		{s: "", want: false},
+		{s: " a", want: false},
+		{s: "no", want: false},
+		{s: "1.5", want: false},
+		{s: "0x10", want: false},
+		{s: "- a", want: false},
+		{s: "a: b", want: false},
+		{s: "a #b", want: false},
+		{s: "a\nb", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := yamlPlain(tt.s); got != tt.want {
+				t.Errorf("yamlPlain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package describe
+
+import (
+	"reflect"
+)
+
+// omitField reports whether a struct field is left out of descriptions, for its describe tag or as it
+// holds a zero value.
+func (d *Describer) omitField(tag string, v reflect.Value) bool {
+	return skipField(tag, v) || d.OmitZero && v.IsZero()
+}
+
+// sparse reports whether the first n elements of an array or slice are written with their indices,
+// leaving out zero values.
+func (w *walker) sparse(t reflect.Type, v reflect.Value, n int) bool {
+	if w.d == nil || !w.d.SparseArrays {
+		return false
+	}
+	if _, ok := w.p.(docPrinter); !ok {
+		return false
+	}
+	zero := 0
+	for i := 0; i < n; i++ {
+		if v.Index(i).IsZero() {
+			zero++
+		}
+	}
+	return zero > 0 && 2*zero >= n
+}
@@ -0,0 +1,99 @@
+package describe
+
+import (
+	"strings"
+	"testing"
+)
+
+type Options struct {
+	Name    string
+	Retries int
+	Tags    []string
+	Inner   Obj
+}
+
+func TestDescriber_OmitZero(t *testing.T) {
+	tests := []struct {
+		name string
+		d    Describer
+		v    interface{}
+		want string
+	}{
+		{
+			name: "some fields",
+			d:    Describer{OmitZero: true},
+			v:    Options{Name: "x", Inner: Obj{Field: 1}},
+			want: "Options{\n\tName: \"x\",\n\tInner: Obj{\n\t\tField: 1,\n\t},\n}",
+		},
+		{
+			name: "all zero",
+			d:    Describer{OmitZero: true},
+			v:    &Options{Tags: []string{}},
+			want: "&Options{\n\tTags: []string{},\n}",
+		},
+		{
+			name: "zero struct",
+			d:    Describer{OmitZero: true},
+			v:    []Options{{}},
+			want: "[]Options{\n\tOptions{},\n}",
+		},
+		{
+			name: "sparse array",
+			d:    Describer{SparseArrays: true, Compact: true},
+			v:    [8]byte{0: 1, 5: 2},
+			want: "[8]uint8{uint8(1), 5: uint8(2)}",
+		},
+		{
+			name: "sparse slice",
+			d:    Describer{SparseArrays: true, Compact: true},
+			v:    []int{0, 0, 3, 4, 0, 0, 0, 0},
+			want: "[]int{2: 3, 4, 7: 0}",
+		},
+		{
+			name: "dense slice",
+			d:    Describer{SparseArrays: true, Compact: true},
+			v:    []int{1, 0, 3},
+			want: "[]int{1, 0, 3}",
+		},
+		{
+			name: "limited zero array",
+			d:    Describer{SparseArrays: true, Compact: true, Limits: Limits{Elements: 2}},
+			v:    [10]int{},
+			want: "[10]int{1: 0 /* 8 more elements */}",
+		},
+		{
+			name: "limited sparse slice",
+			d:    Describer{SparseArrays: true, Compact: true, Limits: Limits{Elements: 2}},
+			v:    []int{0, 0, 0, 0, 5},
+			want: "[]int{1: 0 /* 3 more elements */}",
+		},
+		{
+			name: "zero array",
+			d:    Describer{SparseArrays: true, Compact: true},
+			v:    [4]int{},
+			want: "[4]int{}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.Value(tt.v); got != tt.want {
+				t.Errorf("Describer.Value() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescriber_Print_OmitZero(t *testing.T) {
+	d := &Describer{OmitZero: true}
+	v := map[string]Options{"a": {}}
+	var buf strings.Builder
+	d.Print(v, &yamlPrinter{f: &buf})
+	if got, want := buf.String(), "a: {} # Options"; got != want {
+		t.Errorf("YAML = %q, want %q", got, want)
+	}
+	buf.Reset()
+	d.Print(v, &tomlPrinter{f: &buf})
+	if got, want := buf.String(), "[\"a\"] = {} # Options\n"; got != want {
+		t.Errorf("TOML = %q, want %q", got, want)
+	}
+}